@@ -0,0 +1,42 @@
+package pb
+
+import "time"
+
+// Clock provides time for rendering, timers, elapsed time and speed sampling.
+// Bars and pools use SystemClock by default, pbtest.FakeClock makes tests deterministic.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// Timer returns a channel receiving the time once d elapsed and a function stopping the timer
+	Timer(d time.Duration) (c <-chan time.Time, stop func() bool)
+}
+
+// SystemClock is the real time clock
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Timer(d time.Duration) (<-chan time.Time, func() bool) {
+	t := time.NewTimer(d)
+	return t.C, t.Stop
+}
+
+// SetClock sets the clock of the bar, nil resets it to SystemClock
+func (pb *ProgressBar) SetClock(c Clock) *ProgressBar {
+	pb.mu.Lock()
+	pb.clock = c
+	pb.mu.Unlock()
+	return pb
+}
+
+// getClock returns the clock of the bar, pb.mu must be held
+func (pb *ProgressBar) getClock() Clock {
+	if pb.clock == nil {
+		return SystemClock
+	}
+	return pb.clock
+}
@@ -0,0 +1,49 @@
+package pb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cbehopkins/pb/v3/pbtest"
+)
+
+var _ Clock = (*pbtest.FakeClock)(nil)
+
+func TestPBClock(t *testing.T) {
+	clock := pbtest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	bar := ProgressBarTemplate(`{{etime . }} {{speed . }}`).New(100).SetClock(clock)
+	if a, e := bar.String(), "0s ? p/s"; a != e {
+		t.Errorf("Unexpected result: '%s'; want '%s'", a, e)
+	}
+	clock.Advance(2 * time.Second)
+	bar.SetCurrent(20)
+	if a, e := bar.String(), "2.0s 10 p/s"; a != e {
+		t.Errorf("Unexpected result: '%s'; want '%s'", a, e)
+	}
+}
+
+func TestPBClockWriter(t *testing.T) {
+	clock := pbtest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	screen := pbtest.NewScreen(40, 5)
+	bar := ProgressBarTemplate(`{{counters . }}`).New(10).SetClock(clock).SetWriter(screen)
+	bar.SetRefreshRate(100 * time.Millisecond).SetIdleRefreshRate(-1)
+	bar.Set(ReturnSymbol, "\r").Start()
+	// the first frame is drawn after the refresh rate
+	if !clock.WaitTimers(1, time.Second) {
+		t.Fatalf("Writer doesn't wait on the clock")
+	}
+	clock.Advance(100 * time.Millisecond)
+	if !screen.WaitFrames(1, time.Second) {
+		t.Fatalf("Frame isn't drawn")
+	}
+	bar.SetCurrent(5)
+	if !clock.WaitTimers(1, time.Second) {
+		t.Fatalf("Writer doesn't wait on the clock")
+	}
+	clock.Advance(100 * time.Millisecond)
+	if !screen.WaitFrames(2, time.Second) {
+		t.Fatalf("Frame isn't drawn")
+	}
+	bar.Finish()
+	pbtest.AssertFrames(t, screen, "0 / 10", "5 / 10")
+}
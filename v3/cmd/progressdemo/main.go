@@ -56,6 +56,8 @@ func (m *MockProgressable) SetTitle(title string) {
 func main() {
 	// Create a pool for managing progress bars
 	pool := pb.NewPool()
+	// Tasks are registered one by one, keep drawing until Stop
+	pool.Lifecycle = pb.RunUntilStop
 	if err := pool.Start(); err != nil {
 		log.Fatalf("Failed to start progress bar pool: %v", err)
 	}
@@ -0,0 +1,74 @@
+package pb
+
+import (
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// leftAlignedElements are padded on the right, all others are padded on the left
+var leftAlignedElements = map[string]bool{
+	"string": true,
+}
+
+// columnKey is an element of a template, bars with different templates don't share columns
+type columnKey struct {
+	t     *template.Template
+	index int
+}
+
+// columns shares element widths between bars of a pool, so elements can be padded to a common width
+// Widths are measured on all visible bars before they are rendered, see ProgressBar.measure.
+type columns struct {
+	mu     sync.Mutex
+	widths map[columnKey]int
+}
+
+// frame starts new frame measurement
+func (c *columns) frame() {
+	c.mu.Lock()
+	c.widths = make(map[columnKey]int)
+	c.mu.Unlock()
+}
+
+// record records width of s in the column
+func (c *columns) record(key columnKey, s string) {
+	w := CellCount(s)
+	c.mu.Lock()
+	if w > c.widths[key] {
+		c.widths[key] = w
+	}
+	c.mu.Unlock()
+}
+
+// pad pads s to the widest value of the column
+func (c *columns) pad(key columnKey, s string, left bool) string {
+	c.mu.Lock()
+	max := c.widths[key]
+	c.mu.Unlock()
+	if r := max - CellCount(s); r > 0 {
+		if left {
+			return s + strings.Repeat(" ", r)
+		}
+		return strings.Repeat(" ", r) + s
+	}
+	return s
+}
+
+// column pads element result when bar belongs to an aligned pool
+func (s *State) column(name, result string) string {
+	s.elIndex++
+	if result == adElPlaceholder {
+		// adaptive elements take the rest of width
+		return result
+	}
+	cols, ok := s.Get(columnsObj).(*columns)
+	if !ok || cols == nil {
+		return result
+	}
+	key := columnKey{t: s.tmpl, index: s.elIndex}
+	if s.measure {
+		cols.record(key, result)
+	}
+	return cols.pad(key, result, leftAlignedElements[name])
+}
@@ -0,0 +1,120 @@
+package pb
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// DurationStyle defines how elapsed and remaining time are printed
+// bar.Set(pb.DurationFormat, pb.DurationClock)
+type DurationStyle int
+
+const (
+	// DurationDefault prints Go durations, like a "1h2m3s", or the locale short names
+	DurationDefault DurationStyle = iota
+	// DurationClock prints as a clock: "01:02:03"
+	DurationClock
+	// DurationCompact prints two most significant parts: "1h02m", "2m05s", "45s"
+	DurationCompact
+	// DurationLong prints two most significant parts with the locale long names: "1 hour 2 minutes"
+	DurationLong
+)
+
+// defaultDurationCap limits remaining time, slower ETAs are printed as "> 1 day"
+const defaultDurationCap = 24 * time.Hour
+
+const day = 24 * time.Hour
+
+// durationParts splits d to days, hours, minutes and seconds
+func durationParts(d time.Duration) (parts [4]int64) {
+	parts[0] = int64(d / day)
+	d -= time.Duration(parts[0]) * day
+	parts[1] = int64(d / time.Hour)
+	d -= time.Duration(parts[1]) * time.Hour
+	parts[2] = int64(d / time.Minute)
+	d -= time.Duration(parts[2]) * time.Minute
+	parts[3] = int64(d / time.Second)
+	return
+}
+
+func formatDurationClock(d time.Duration) string {
+	h := int64(d / time.Hour)
+	d -= time.Duration(h) * time.Hour
+	m := int64(d / time.Minute)
+	d -= time.Duration(m) * time.Minute
+	return fmt.Sprintf("%02d:%02d:%02d", h, m, int64(d/time.Second))
+}
+
+var compactNames = [4]string{"d", "h", "m", "s"}
+
+func formatDurationCompact(d time.Duration) string {
+	parts := durationParts(d)
+	for i, p := range parts[:3] {
+		if p > 0 {
+			return fmt.Sprintf("%d%s%02d%s", p, compactNames[i], parts[i+1], compactNames[i+1])
+		}
+	}
+	return fmt.Sprintf("%ds", parts[3])
+}
+
+func (l *Locale) formatDurationLong(d time.Duration) string {
+	names := [4][2]string{l.LongDay, l.LongHour, l.LongMinute, l.LongSecond}
+	if names[3][0] == "" {
+		names = [4][2]string{LocaleEnglish.LongDay, LocaleEnglish.LongHour, LocaleEnglish.LongMinute, LocaleEnglish.LongSecond}
+	}
+	word := func(i int, v int64) string {
+		name := names[i][1]
+		if v == 1 {
+			name = names[i][0]
+		}
+		return strconv.FormatInt(v, 10) + l.LongSpace + name
+	}
+	parts := durationParts(d)
+	for i, p := range parts[:3] {
+		if p > 0 {
+			if parts[i+1] == 0 {
+				return word(i, p)
+			}
+			return word(i, p) + l.LongSpace + word(i+1, parts[i+1])
+		}
+	}
+	return word(3, parts[3])
+}
+
+// durationStyle returns style set by DurationFormat
+func (pb *ProgressBar) durationStyle() DurationStyle {
+	style, _ := pb.Get(DurationFormat).(DurationStyle)
+	return style
+}
+
+// FormatDuration convert duration to string according to the DurationFormat and locale
+func (pb *ProgressBar) FormatDuration(d time.Duration) string {
+	l := pb.locale()
+	switch pb.durationStyle() {
+	case DurationClock:
+		return formatDurationClock(d)
+	case DurationCompact:
+		return formatDurationCompact(d)
+	case DurationLong:
+		return l.formatDurationLong(d)
+	}
+	return l.FormatDuration(d)
+}
+
+// remainingTime converts remaining seconds to string, values above DurationCap are printed like a "> 1 day"
+func (pb *ProgressBar) remainingTime(seconds float64) string {
+	limit, ok := pb.Get(DurationCap).(time.Duration)
+	if !ok {
+		limit = defaultDurationCap
+	}
+	if limit > 0 && seconds > limit.Seconds() {
+		l := pb.locale()
+		return fmt.Sprintf(l.String("> %s"), l.formatDurationLong(limit))
+	}
+	if seconds > float64(math.MaxInt64/int64(time.Second)) {
+		seconds = float64(math.MaxInt64 / int64(time.Second))
+	}
+	return pb.FormatDuration(time.Duration(seconds) * time.Second)
+}
@@ -0,0 +1,68 @@
+package pb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDurationStyles(t *testing.T) {
+	d := time.Hour + 2*time.Minute + 3*time.Second
+	inputs := []struct {
+		style DurationStyle
+		l     *Locale
+		d     time.Duration
+		e     string
+	}{
+		{style: DurationDefault, d: d, e: "1h2m3s"},
+		{style: DurationClock, d: d, e: "01:02:03"},
+		{style: DurationClock, d: 26 * time.Hour, e: "26:00:00"},
+		{style: DurationCompact, d: d, e: "1h02m"},
+		{style: DurationCompact, d: 2*time.Minute + 5*time.Second, e: "2m05s"},
+		{style: DurationCompact, d: 45 * time.Second, e: "45s"},
+		{style: DurationCompact, d: 50 * time.Hour, e: "2d02h"},
+		{style: DurationLong, d: d, e: "1 hour 2 minutes"},
+		{style: DurationLong, d: time.Hour, e: "1 hour"},
+		{style: DurationLong, d: time.Second, e: "1 second"},
+		{style: DurationLong, d: 0, e: "0 seconds"},
+		{style: DurationLong, l: LocaleGerman, d: d, e: "1 Stunde 2 Minuten"},
+		{style: DurationLong, l: LocaleJapanese, d: d, e: "1時間2分"},
+	}
+	for _, input := range inputs {
+		bar := new(ProgressBar).Set(DurationFormat, input.style).Set(Localization, input.l)
+		if a := bar.FormatDuration(input.d); a != input.e {
+			t.Errorf("Unexpected duration: actual: '%v'; expected: '%v'", a, input.e)
+		}
+	}
+}
+
+func TestRemainingTimeCap(t *testing.T) {
+	bar := new(ProgressBar)
+	if a, e := bar.remainingTime(90), "1m30s"; a != e {
+		t.Errorf("Unexpected result: actual: '%v'; expected: '%v'", a, e)
+	}
+	if a, e := bar.remainingTime(1e15), "> 1 day"; a != e {
+		t.Errorf("Unexpected result: actual: '%v'; expected: '%v'", a, e)
+	}
+	bar.Set(DurationCap, time.Hour)
+	if a, e := bar.remainingTime(3601), "> 1 hour"; a != e {
+		t.Errorf("Unexpected result: actual: '%v'; expected: '%v'", a, e)
+	}
+	bar.Set(Localization, "ja")
+	if a, e := bar.remainingTime(3601), "1時間以上"; a != e {
+		t.Errorf("Unexpected result: actual: '%v'; expected: '%v'", a, e)
+	}
+	bar.Set(DurationCap, time.Duration(-1)).Set(Localization, nil)
+	if a, e := bar.remainingTime(1e15), "2562047h47m16s"; a != e {
+		t.Errorf("Unexpected result: actual: '%v'; expected: '%v'", a, e)
+	}
+}
+
+func TestElapsedTimeStyle(t *testing.T) {
+	var state = testState(1000, 0, 0, false)
+	state.Set(DurationFormat, DurationClock)
+	state.startTime = time.Now()
+	state.time = state.startTime.Add(time.Hour + 5*time.Second)
+	if r, w := ElementElapsedTime(state), "01:00:05"; r != w {
+		t.Errorf("Unexpected result: '%s' vs '%s'", r, w)
+	}
+}
@@ -45,9 +45,11 @@ var elements = map[string]Element{
 }
 
 // RegisterElement give you a chance to use custom elements
-func RegisterElement(name string, el Element, adaptive bool) {
+// Adaptive elements share the rest of bar width, opts may set their weight and width limits:
+// pb.RegisterElement("msg", myEl, true, pb.AdaptiveWeight(2), pb.AdaptiveMaxWidth(40))
+func RegisterElement(name string, el Element, adaptive bool, opts ...AdaptiveOption) {
 	if adaptive {
-		el = adaptiveWrap(el)
+		el = adaptiveWrap(el, opts...)
 	}
 	elementsM.Lock()
 	elements[name] = el
@@ -70,32 +72,88 @@ func (args argsHelper) getNotEmptyOr(n int, value string) (v string) {
 	return
 }
 
-func adaptiveWrap(el Element) Element {
+// adaptiveOpts holds layout options of adaptive element
+type adaptiveOpts struct {
+	weight, min, max int
+}
+
+// AdaptiveOption sets layout option of adaptive element
+type AdaptiveOption func(o *adaptiveOpts)
+
+// AdaptiveWeight sets share of the rest width relative to other adaptive elements. Defaults to 1.
+// In template use as follows: {{bar . | weight . 2}}
+func AdaptiveWeight(w int) AdaptiveOption {
+	return func(o *adaptiveOpts) {
+		if w > 0 {
+			o.weight = w
+		}
+	}
+}
+
+// AdaptiveMinWidth sets minimal width of adaptive element, when there is enough space
+// In template use as follows: {{bar . | minwidth . 10}}
+func AdaptiveMinWidth(w int) AdaptiveOption {
+	return func(o *adaptiveOpts) {
+		o.min = w
+	}
+}
+
+// AdaptiveMaxWidth sets maximal width of adaptive element, the rest goes to other adaptive elements
+// In template use as follows: {{bar . | maxwidth . 40}}
+func AdaptiveMaxWidth(w int) AdaptiveOption {
+	return func(o *adaptiveOpts) {
+		o.max = w
+	}
+}
+
+// adaptiveElement is an adaptive element waiting for its width
+type adaptiveElement struct {
+	Element
+	adaptiveOpts
+}
+
+func adaptiveWrap(el Element, opts ...AdaptiveOption) Element {
+	o := adaptiveOpts{weight: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	return ElementFunc(func(state *State, args ...string) string {
-		state.recalc = append(state.recalc, ElementFunc(func(s *State, _ ...string) (result string) {
-			s.adaptive = true
-			result = el.ProgressElement(s, args...)
-			s.adaptive = false
-			return
-		}))
+		state.recalc = append(state.recalc, adaptiveElement{
+			Element: ElementFunc(func(s *State, _ ...string) (result string) {
+				s.adaptive = true
+				result = el.ProgressElement(s, args...)
+				s.adaptive = false
+				return
+			}),
+			adaptiveOpts: o,
+		})
 		return adElPlaceholder
 	})
 }
 
+// adaptiveOptionFunc makes template func which applies option to the adaptive element given by pipeline
+func adaptiveOptionFunc(opt func(int) AdaptiveOption) func(state *State, v int, s string) string {
+	return func(state *State, v int, s string) string {
+		if s == adElPlaceholder && len(state.recalc) > 0 {
+			opt(v)(&state.recalc[len(state.recalc)-1].adaptiveOpts)
+		}
+		return s
+	}
+}
+
 // ElementPercent shows current percent of progress.
 // Optionally can take one or two string arguments.
-// First string will be used as value for format float64, default is "%.02f%%".
+// First string will be used as value for format float64, default is "%.02f%%". The number uses the locale decimal separator.
 // Second string will be used when percent can't be calculated, default is "?%"
 // In template use as follows: {{percent .}} or {{percent . "%.03f%%"}} or {{percent . "%.03f%%" "?"}}
 var ElementPercent ElementFunc = func(state *State, args ...string) string {
 	argsh := argsHelper(args)
+	l := state.locale()
 	if state.Total() > 0 {
-		return fmt.Sprintf(
-			argsh.getNotEmptyOr(0, "%.02f%%"),
-			float64(state.Value())/(float64(state.Total())/float64(100)),
-		)
+		percent := float64(state.Value()) / (float64(state.Total()) / float64(100))
+		return fmt.Sprintf(l.String(argsh.getNotEmptyOr(0, "%.02f%%")), l.float(percent))
 	}
-	return argsh.getOr(1, "?%")
+	return l.String(argsh.getOr(1, "?%"))
 }
 
 // ElementCounters shows current and total values.
@@ -110,7 +168,7 @@ var ElementCounters ElementFunc = func(state *State, args ...string) string {
 	} else {
 		f = argsHelper(args).getNotEmptyOr(1, "%[1]s")
 	}
-	return fmt.Sprintf(f, state.Format(state.Value()), state.Format(state.Total()))
+	return fmt.Sprintf(f, state.formatCounter(state.Value()), state.formatCounter(state.Total()))
 }
 
 type elementKey int
@@ -119,6 +177,7 @@ const (
 	barObj elementKey = iota
 	speedObj
 	cycleObj
+	columnsObj
 )
 
 type bar struct {
@@ -234,6 +293,7 @@ var ElementBar ElementFunc = func(state *State, args ...string) string {
 
 func elapsedTime(state *State) string {
 	elapsed := state.Time().Sub(state.StartTime())
+	l := state.locale()
 	var precision time.Duration
 	var ok bool
 	if precision, ok = state.Get(TimeRound).(time.Duration); !ok {
@@ -250,6 +310,12 @@ func elapsedTime(state *State) string {
 		}
 	}
 	rounded := elapsed.Round(precision)
+	if state.durationStyle() != DurationDefault {
+		return state.FormatDuration(rounded)
+	}
+	if l.Second != "" {
+		return l.formatDuration(rounded, durationDecimals(precision))
+	}
 	if precision < time.Second && rounded >= time.Second {
 		// special handling to ensure string is shown with the given
 		// precision, with trailing zeros after the decimal point if
@@ -274,30 +340,40 @@ func elapsedTime(state *State) string {
 	}
 }
 
+// durationDecimals returns count of decimal digits needed to show seconds with given precision
+func durationDecimals(precision time.Duration) (n int) {
+	for p := precision; p > 0 && p < time.Second; p *= 10 {
+		n++
+	}
+	return
+}
+
 // ElementRemainingTime calculates remaining time based on speed (EWMA)
 // Optionally can take one or two string arguments.
 // First string will be used as value for format time duration string, default is "%s".
 // Second string will be used when bar finished and value indicates elapsed time, default is "%s"
 // Third string will be used when value not available, default is "?"
 // In template use as follows: {{rtime .}} or {{rtime . "%s remain"}} or {{rtime . "%s remain" "%s total" "???"}}
+// Format strings are translated by the bar locale. Time is printed according to DurationFormat and DurationCap.
 var ElementRemainingTime ElementFunc = func(state *State, args ...string) string {
+	l := state.locale()
 	if state.IsFinished() {
-		return fmt.Sprintf(argsHelper(args).getOr(1, "%s"), elapsedTime(state))
+		return fmt.Sprintf(l.String(argsHelper(args).getOr(1, "%s")), elapsedTime(state))
 	}
 	sp := getSpeedObj(state).value(state)
 	if sp > 0 {
 		remain := float64(state.Total() - state.Value())
-		remainDur := time.Duration(remain/sp) * time.Second
-		return fmt.Sprintf(argsHelper(args).getOr(0, "%s"), remainDur)
+		return fmt.Sprintf(l.String(argsHelper(args).getOr(0, "%s")), state.remainingTime(remain/sp))
 	}
-	return argsHelper(args).getOr(2, "?")
+	return l.String(argsHelper(args).getOr(2, "?"))
 }
 
 // ElementElapsedTime shows elapsed time
-// Optionally can take one argument - it's format for time string.
+// Optionally can take one argument - it's format for time string, translated by the bar locale.
+// Time is printed according to DurationFormat.
 // In template use as follows: {{etime .}} or {{etime . "%s elapsed"}}
 var ElementElapsedTime ElementFunc = func(state *State, args ...string) string {
-	return fmt.Sprintf(argsHelper(args).getOr(0, "%s"), elapsedTime(state))
+	return fmt.Sprintf(state.locale().String(argsHelper(args).getOr(0, "%s")), elapsedTime(state))
 }
 
 // ElementString get value from bar by given key and print them
@@ -325,6 +401,8 @@ var ElementCycle ElementFunc = func(state *State, args ...string) string {
 	if n >= len(args) {
 		n = 0
 	}
-	state.Set(cycleObj, n+1)
+	if !state.dry {
+		state.Set(cycleObj, n+1)
+	}
 	return args[n]
 }
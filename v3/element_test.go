@@ -52,6 +52,9 @@ func TestElementCounters(t *testing.T) {
 	testElementBarString(t, testState(100, 50, 0, true), ElementCounters, "50 B / 100 B")
 	testElementBarString(t, testState(0, 50, 0, true), ElementCounters, "50 B")
 	testElementBarString(t, testState(0, 50, 0, true), ElementCounters, "50 B / ?", "", "%[1]s / ?")
+	state := testState(100, 50, 0)
+	state.Set(CountWidth, 5)
+	testElementBarString(t, state, ElementCounters, "   50 /   100")
 }
 
 func TestElementBar(t *testing.T) {
@@ -119,6 +119,13 @@ func progressWorker(ctx context.Context, pr Progressable, bar *ProgressBar, remo
 	}
 }
 
+const (
+	// LingerRemove removes finished bars from the pool immediately.
+	LingerRemove time.Duration = 0
+	// LingerUntilStop keeps finished bars in the pool until it stops.
+	LingerUntilStop time.Duration = -1
+)
+
 // PoolProgressFactory creates and manages progress bars for a pb.Pool.
 // It wraps a Pool and a WaitGroup to simplify adding multiple Progressables
 // to a pool while tracking their completion.
@@ -127,6 +134,12 @@ type PoolProgressFactory struct {
 	Pool *Pool
 	// Wg is a WaitGroup that tracks the completion of all registered progressables.
 	Wg *sync.WaitGroup
+	// Linger is how long a finished bar stays in the pool before it is removed.
+	// Use LingerRemove (the default) to remove it immediately or LingerUntilStop to keep it.
+	// Linger doesn't apply when the pool has PersistFinished set.
+	Linger time.Duration
+	// FinishedTemplate, when set, replaces the template of a lingering finished bar, e.g. Done.
+	FinishedTemplate ProgressBarTemplate
 }
 
 // NewPoolProgressFactory creates a new PoolProgressFactory for the given pool.
@@ -162,8 +175,25 @@ func (f *PoolProgressFactory) RegisterContext(ctx context.Context, p Progressabl
 
 	f.Wg.Add(1)
 	removeFunc := func(pb *ProgressBar) {
-		f.Pool.Remove(pb)
-		f.Wg.Done()
+		defer f.Wg.Done()
+		// with PersistFinished the pool prints the final state of the bar and drops it itself
+		if f.Pool.PersistFinished {
+			return
+		}
+		if f.Linger == LingerRemove {
+			f.Pool.Remove(pb)
+			return
+		}
+		if f.FinishedTemplate != "" {
+			pb.SetTemplate(f.FinishedTemplate)
+		}
+		if f.Linger > 0 {
+			c, _ := f.Pool.clock().Timer(f.Linger)
+			go func() {
+				<-c
+				f.Pool.Remove(pb)
+			}()
+		}
 	}
 
 	bar, err := RegisterProgressableContext(ctx, p, removeFunc)
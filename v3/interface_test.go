@@ -3,9 +3,12 @@ package pb
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/cbehopkins/pb/v3/pbtest"
 )
 
 // MockProgressable is a test implementation of the Progressable interface
@@ -477,3 +480,73 @@ func TestPoolProgressFactoryRegisterError(t *testing.T) {
 		t.Error("Factory WaitGroup blocked after Register error")
 	}
 }
+
+// TestPoolProgressFactoryLinger verifies finished bars stay in the pool for the linger time
+func TestPoolProgressFactoryLinger(t *testing.T) {
+	inputs := []struct {
+		linger time.Duration
+		bars   []int // expected count of bars right after finish and after linger
+	}{
+		{linger: LingerRemove, bars: []int{0, 0}},
+		{linger: 100 * time.Millisecond, bars: []int{1, 0}},
+		{linger: LingerUntilStop, bars: []int{1, 1}},
+	}
+	count := func(pool *Pool) int {
+		pool.m.Lock()
+		defer pool.m.Unlock()
+		return len(pool.bars)
+	}
+	for _, input := range inputs {
+		clock := pbtest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+		pool := NewPool()
+		pool.Clock = clock
+		factory := NewPoolProgressFactory(pool)
+		factory.Linger = input.linger
+		factory.FinishedTemplate = Done
+		mock := NewMockProgressableWithTitle(10, "task")
+		if err := factory.Register(mock); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		mock.SetCurrent(10)
+		mock.Finish()
+		factory.Wg.Wait()
+		if a := count(pool); a != input.bars[0] {
+			t.Errorf("Unexpected bars count after finish for %v: %d", input.linger, a)
+		}
+		if a := count(pool); a > 0 {
+			pool.m.Lock()
+			bar := pool.bars[0]
+			pool.m.Unlock()
+			if s := bar.String(); !strings.Contains(s, "✓") || !strings.Contains(s, "task 10 / 10") {
+				t.Errorf("Unexpected lingering bar: %q", s)
+			}
+		}
+		// only a positive linger time waits on the clock
+		var timers int
+		if input.linger > 0 {
+			timers = 1
+		}
+		if a := clock.Timers(); a != timers {
+			t.Errorf("Unexpected timers count for %v: %d", input.linger, a)
+		}
+		pool.m.Lock()
+		wake := pool.wakeChan()
+		pool.m.Unlock()
+		select {
+		case <-wake:
+		default:
+		}
+		clock.Advance(time.Hour)
+		if input.linger > 0 {
+			// Remove notifies the pool once the bar is removed
+			select {
+			case <-wake:
+			case <-time.After(2 * time.Second):
+				t.Fatalf("Bar isn't removed after linger")
+			}
+		}
+		if a := count(pool); a != input.bars[1] {
+			t.Errorf("Unexpected bars count after linger for %v: %d", input.linger, a)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package pb
+
+import "sort"
+
+// adaptiveLayout distributes width between adaptive elements according to their weights,
+// respecting minimal and maximal widths. Remainder cells of integer division are given
+// to elements with the largest fractional parts.
+func adaptiveLayout(els []adaptiveElement, width int) []int {
+	widths := make([]int, len(els))
+	fixed := make([]bool, len(els))
+	for {
+		rest, weights := width, 0
+		for i, el := range els {
+			if fixed[i] {
+				rest -= widths[i]
+			} else {
+				weights += el.weight
+			}
+		}
+		if weights == 0 {
+			return widths
+		}
+		if rest < 0 {
+			rest = 0
+		}
+		shares := weightedShares(els, fixed, rest, weights)
+		var clamped bool
+		for i, el := range els {
+			if fixed[i] {
+				continue
+			}
+			switch {
+			case el.max > 0 && shares[i] > el.max:
+				widths[i], fixed[i], clamped = el.max, true, true
+			case shares[i] < el.min:
+				widths[i], fixed[i], clamped = el.min, true, true
+			}
+		}
+		if !clamped {
+			for i := range els {
+				if !fixed[i] {
+					widths[i] = shares[i]
+				}
+			}
+			return widths
+		}
+	}
+}
+
+// weightedShares splits rest between not fixed elements using the largest remainder method
+func weightedShares(els []adaptiveElement, fixed []bool, rest, weights int) []int {
+	shares := make([]int, len(els))
+	var order []int
+	left := rest
+	for i, el := range els {
+		if fixed[i] {
+			continue
+		}
+		shares[i] = rest * el.weight / weights
+		left -= shares[i]
+		order = append(order, i)
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ra := rest * els[order[a]].weight % weights
+		rb := rest * els[order[b]].weight % weights
+		return ra > rb
+	})
+	for _, i := range order {
+		if left == 0 {
+			break
+		}
+		shares[i]++
+		left--
+	}
+	return shares
+}
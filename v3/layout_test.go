@@ -0,0 +1,62 @@
+package pb
+
+import (
+	"strings"
+	"testing"
+)
+
+func testAdaptiveEls(opts ...adaptiveOpts) (els []adaptiveElement) {
+	for _, o := range opts {
+		if o.weight == 0 {
+			o.weight = 1
+		}
+		els = append(els, adaptiveElement{adaptiveOpts: o})
+	}
+	return
+}
+
+func TestAdaptiveLayout(t *testing.T) {
+	inputs := []struct {
+		els   []adaptiveElement
+		width int
+		e     []int
+	}{
+		{els: testAdaptiveEls(adaptiveOpts{}), width: 7, e: []int{7}},
+		{els: testAdaptiveEls(adaptiveOpts{}, adaptiveOpts{}), width: 7, e: []int{4, 3}},
+		{els: testAdaptiveEls(adaptiveOpts{}, adaptiveOpts{}, adaptiveOpts{}), width: 10, e: []int{4, 3, 3}},
+		{els: testAdaptiveEls(adaptiveOpts{weight: 3}, adaptiveOpts{}), width: 10, e: []int{8, 2}},
+		{els: testAdaptiveEls(adaptiveOpts{weight: 3, max: 5}, adaptiveOpts{}), width: 10, e: []int{5, 5}},
+		{els: testAdaptiveEls(adaptiveOpts{weight: 3}, adaptiveOpts{min: 4}), width: 10, e: []int{6, 4}},
+		{els: testAdaptiveEls(adaptiveOpts{max: 2}, adaptiveOpts{max: 3}), width: 10, e: []int{2, 3}},
+		{els: testAdaptiveEls(adaptiveOpts{min: 8}, adaptiveOpts{min: 8}), width: 10, e: []int{8, 8}},
+	}
+	for n, input := range inputs {
+		widths := adaptiveLayout(input.els, input.width)
+		for i := range input.e {
+			if widths[i] != input.e[i] {
+				t.Errorf("Unexpected widths[%d]: %v; want %v", n, widths, input.e)
+				break
+			}
+		}
+	}
+}
+
+func TestAdaptiveLayoutTemplate(t *testing.T) {
+	var testEl ElementFunc = func(state *State, args ...string) string {
+		return strings.Repeat(args[0], state.AdaptiveElWidth())
+	}
+	RegisterElement("testLayoutEl", testEl, true, AdaptiveWeight(2))
+	bar := ProgressBarTemplate(`{{testLayoutEl . "a"}}|{{testLayoutEl . "b" | weight . 1}}`).New(0).SetWidth(11)
+	if a, e := bar.String(), "aaaaaaa|bbb"; a != e {
+		t.Errorf("Unexpected result: '%v'; want '%v'", a, e)
+	}
+	bar.SetTemplateString(`{{testLayoutEl . "a" | maxwidth . 3}}|{{testLayoutEl . "b" | minwidth . 2}}`)
+	if a, e := bar.String(), "aaa|bbbbbbb"; a != e {
+		t.Errorf("Unexpected result: '%v'; want '%v'", a, e)
+	}
+	// remainder cells are not lost
+	bar.SetTemplateString(`{{bar . }}{{bar . }}`).SetWidth(9)
+	if a := CellCount(bar.String()); a != 9 {
+		t.Errorf("Unexpected width: %d; want 9", a)
+	}
+}
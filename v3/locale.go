@@ -0,0 +1,228 @@
+package pb
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Locale holds language specific number formatting, duration and unit names and element strings
+// Select locale for the bar by bar.Set(pb.Localization, "de") or globally by SetDefaultLocale
+type Locale struct {
+	// Name of the locale in the registry
+	Name string
+
+	// DecimalSeparator is used instead of "." in fractional numbers. Defaults to "."
+	DecimalSeparator string
+
+	// ThousandsSeparator is the default separator for CountGrouped
+	ThousandsSeparator string
+
+	// Hour, Minute and Second names used for durations. When Second is empty Go duration format is used: "1h2m3s"
+	Hour, Minute, Second string
+
+	// DurationSpace is placed between numbers and names and between parts of durations: "3 Min 2 Sek"
+	DurationSpace string
+
+	// LongDay, LongHour, LongMinute and LongSecond are singular and plural names for DurationLong: {"hour", "hours"}
+	LongDay, LongHour, LongMinute, LongSecond [2]string
+
+	// LongSpace is placed between numbers and names and between parts of DurationLong: "1 hour 2 minutes"
+	LongSpace string
+
+	// Units translates unit symbols and rate periods: "p", "files", "s", "min", "h"
+	Units map[string]string
+
+	// Strings translates default element strings and format arguments: "?%", "?", "ETA %s"
+	Strings map[string]string
+}
+
+var (
+	// LocaleEnglish is the default locale
+	LocaleEnglish = &Locale{
+		Name:               "en",
+		DecimalSeparator:   ".",
+		ThousandsSeparator: ",",
+		LongDay:            [2]string{"day", "days"},
+		LongHour:           [2]string{"hour", "hours"},
+		LongMinute:         [2]string{"minute", "minutes"},
+		LongSecond:         [2]string{"second", "seconds"},
+		LongSpace:          " ",
+	}
+
+	// LocaleGerman prints "1,20 MiB", "3 Min 2 Sek", "Restzeit 5 Sek"
+	LocaleGerman = &Locale{
+		Name:               "de",
+		DecimalSeparator:   ",",
+		ThousandsSeparator: ".",
+		Hour:               "Std",
+		Minute:             "Min",
+		Second:             "Sek",
+		DurationSpace:      " ",
+		LongDay:            [2]string{"Tag", "Tage"},
+		LongHour:           [2]string{"Stunde", "Stunden"},
+		LongMinute:         [2]string{"Minute", "Minuten"},
+		LongSecond:         [2]string{"Sekunde", "Sekunden"},
+		LongSpace:          " ",
+		Units: map[string]string{
+			"p":     "St.",
+			"files": "Dateien",
+			"rows":  "Zeilen",
+			"min":   "Min",
+			"h":     "Std",
+		},
+		Strings: map[string]string{
+			"ETA %s": "Restzeit %s",
+		},
+	}
+
+	// LocaleJapanese prints "3分2秒", "残り 5秒"
+	LocaleJapanese = &Locale{
+		Name:               "ja",
+		DecimalSeparator:   ".",
+		ThousandsSeparator: ",",
+		Hour:               "時間",
+		Minute:             "分",
+		Second:             "秒",
+		LongDay:            [2]string{"日", "日"},
+		LongHour:           [2]string{"時間", "時間"},
+		LongMinute:         [2]string{"分", "分"},
+		LongSecond:         [2]string{"秒", "秒"},
+		Units: map[string]string{
+			"p":     "件",
+			"files": "ファイル",
+			"rows":  "行",
+			"s":     "秒",
+			"min":   "分",
+			"h":     "時間",
+		},
+		Strings: map[string]string{
+			"ETA %s": "残り %s",
+			"> %s":   "%s以上",
+		},
+	}
+)
+
+var localesM sync.Mutex
+
+var locales = map[string]*Locale{
+	LocaleEnglish.Name:  LocaleEnglish,
+	LocaleGerman.Name:   LocaleGerman,
+	LocaleJapanese.Name: LocaleJapanese,
+}
+
+var defaultLocale = LocaleEnglish
+
+// RegisterLocale adds the locale to the registry, so it can be selected by name
+func RegisterLocale(l Locale) {
+	localesM.Lock()
+	locales[l.Name] = &l
+	localesM.Unlock()
+}
+
+// LookupLocale returns registered locale by name or nil
+func LookupLocale(name string) *Locale {
+	localesM.Lock()
+	defer localesM.Unlock()
+	return locales[name]
+}
+
+// SetDefaultLocale sets locale for all bars without Localization setting. Pass nil to reset to English.
+func SetDefaultLocale(l *Locale) {
+	if l == nil {
+		l = LocaleEnglish
+	}
+	localesM.Lock()
+	defaultLocale = l
+	localesM.Unlock()
+}
+
+// Unit returns translated unit symbol or the symbol itself
+func (l *Locale) Unit(symbol string) string {
+	if s, ok := l.Units[symbol]; ok {
+		return s
+	}
+	return symbol
+}
+
+// String returns translated string or the string itself
+func (l *Locale) String(s string) string {
+	if t, ok := l.Strings[s]; ok {
+		return t
+	}
+	return s
+}
+
+// Number replaces decimal point in formatted number by the locale separator
+func (l *Locale) Number(s string) string {
+	if l.DecimalSeparator == "" || l.DecimalSeparator == "." {
+		return s
+	}
+	return strings.Replace(s, ".", l.DecimalSeparator, 1)
+}
+
+// float wraps v, so it's formatted with any verb and the locale decimal separator
+func (l *Locale) float(v float64) fmt.Formatter {
+	return localeFloat{v: v, l: l}
+}
+
+type localeFloat struct {
+	v float64
+	l *Locale
+}
+
+func (f localeFloat) Format(s fmt.State, verb rune) {
+	io.WriteString(s, f.l.Number(fmt.Sprintf(fmt.FormatString(s, verb), f.v)))
+}
+
+// FormatDuration convert duration to string, like a "1h2m3s" or "1 Std 2 Min 3 Sek"
+func (l *Locale) FormatDuration(d time.Duration) string {
+	return l.formatDuration(d, -1)
+}
+
+// formatDuration prints seconds with given count of decimals, -1 means as many as needed
+func (l *Locale) formatDuration(d time.Duration, decimals int) string {
+	if l.Second == "" {
+		return d.String()
+	}
+	var sign string
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	var parts []string
+	part := func(n, name string) {
+		parts = append(parts, n+l.DurationSpace+name)
+	}
+	if h := d / time.Hour; h > 0 {
+		part(strconv.FormatInt(int64(h), 10), l.Hour)
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 || len(parts) > 0 {
+		part(strconv.FormatInt(int64(m), 10), l.Minute)
+		d -= m * time.Minute
+	}
+	part(l.Number(strconv.FormatFloat(d.Seconds(), 'f', decimals, 64)), l.Second)
+	return sign + strings.Join(parts, l.DurationSpace)
+}
+
+// locale returns the locale selected by Localization or the default one
+func (pb *ProgressBar) locale() *Locale {
+	switch l := pb.Get(Localization).(type) {
+	case *Locale:
+		if l != nil {
+			return l
+		}
+	case Locale:
+		return &l
+	case string:
+		if l := LookupLocale(l); l != nil {
+			return l
+		}
+	}
+	localesM.Lock()
+	defer localesM.Unlock()
+	return defaultLocale
+}
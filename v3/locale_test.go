@@ -0,0 +1,126 @@
+package pb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocaleFormatDuration(t *testing.T) {
+	inputs := []struct {
+		l *Locale
+		d time.Duration
+		e string
+	}{
+		{l: LocaleEnglish, d: 3*time.Minute + 2*time.Second, e: "3m2s"},
+		{l: LocaleGerman, d: 3*time.Minute + 2*time.Second, e: "3 Min 2 Sek"},
+		{l: LocaleGerman, d: time.Hour + 2*time.Second, e: "1 Std 0 Min 2 Sek"},
+		{l: LocaleGerman, d: 1500 * time.Millisecond, e: "1,5 Sek"},
+		{l: LocaleGerman, d: -5 * time.Second, e: "-5 Sek"},
+		{l: LocaleJapanese, d: 3*time.Minute + 2*time.Second, e: "3分2秒"},
+	}
+	for _, input := range inputs {
+		if a := input.l.FormatDuration(input.d); a != input.e {
+			t.Errorf("Unexpected duration: actual: '%v'; expected: '%v'", a, input.e)
+		}
+	}
+}
+
+func TestLocaleBar(t *testing.T) {
+	bar := new(ProgressBar).Set(Localization, "de")
+	bar.Set(Bytes, true)
+	if a, e := bar.Format(1536), "1,50 KiB"; a != e {
+		t.Errorf("Unexpected format: actual: '%v'; expected: '%v'", a, e)
+	}
+	bar.Set(Bytes, false).Set(Counts, CountGrouped)
+	if a, e := bar.Format(1234567), "1.234.567"; a != e {
+		t.Errorf("Unexpected format: actual: '%v'; expected: '%v'", a, e)
+	}
+	bar.Set(Counts, CountSI)
+	if a, e := bar.Format(1234567), "1,2M"; a != e {
+		t.Errorf("Unexpected format: actual: '%v'; expected: '%v'", a, e)
+	}
+	if a, e := bar.FormatSpeed(60), "60 St./s"; a != e {
+		t.Errorf("Unexpected speed: actual: '%v'; expected: '%v'", a, e)
+	}
+
+	bar.SetTotal(0).SetTemplateString(`{{percent . }} {{speed . }} {{rtime . "ETA %s"}}`)
+	if a, e := bar.String(), "?% ? St./s ?"; a != e {
+		t.Errorf("Unexpected result: actual: '%v'; expected: '%v'", a, e)
+	}
+	bar.SetTotal(3).SetCurrent(1)
+	if a, e := bar.String(), "33,33% ? St./s ?"; a != e {
+		t.Errorf("Unexpected result: actual: '%v'; expected: '%v'", a, e)
+	}
+}
+
+func TestLocaleElements(t *testing.T) {
+	var state = testState(100, 0, 0, false)
+	state.Set(Localization, LocaleJapanese)
+	state.time = time.Now()
+	state.startTime = state.time
+	var r string
+	for i := int64(0); i < 3; i++ {
+		state.id = uint64(i) + 1
+		state.time = state.time.Add(time.Second)
+		r = ElementRemainingTime(state, "ETA %s")
+		state.current += 10
+	}
+	if w := "残り 8秒"; r != w {
+		t.Errorf("Unexpected result: '%s' vs '%s'", r, w)
+	}
+	if r, w := ElementElapsedTime(state), "3.0秒"; r != w {
+		t.Errorf("Unexpected result: '%s' vs '%s'", r, w)
+	}
+}
+
+// testRegisterLocale registers the locale until the end of the test
+func testRegisterLocale(t *testing.T, l Locale) {
+	localesM.Lock()
+	prev, ok := locales[l.Name]
+	localesM.Unlock()
+	RegisterLocale(l)
+	t.Cleanup(func() {
+		localesM.Lock()
+		defer localesM.Unlock()
+		if ok {
+			locales[l.Name] = prev
+		} else {
+			delete(locales, l.Name)
+		}
+	})
+}
+
+func TestLocalePercent(t *testing.T) {
+	state := testState(100, 50, 0)
+	state.Set(Localization, LocaleGerman)
+	testElementBarString(t, state, ElementPercent, "50,00%")
+	testElementBarString(t, state, ElementPercent, "50,0%", "%.1f%%")
+	testElementBarString(t, state, ElementPercent, "ca. 50,000 Prozent", "ca. %.3f Prozent")
+}
+
+func TestSetDefaultLocale(t *testing.T) {
+	defer SetDefaultLocale(nil)
+	testRegisterLocale(t, Locale{Name: "test", DecimalSeparator: "_"})
+	SetDefaultLocale(LookupLocale("test"))
+	bar := new(ProgressBar).Set(Counts, CountSI)
+	if a, e := bar.Format(1500), "1_5k"; a != e {
+		t.Errorf("Unexpected format: actual: '%v'; expected: '%v'", a, e)
+	}
+	bar.Set(Localization, "en")
+	if a, e := bar.Format(1500), "1.5k"; a != e {
+		t.Errorf("Unexpected format: actual: '%v'; expected: '%v'", a, e)
+	}
+}
+
+func TestLocaleRegistryCleanup(t *testing.T) {
+	t.Run("register", func(t *testing.T) {
+		testRegisterLocale(t, Locale{Name: "test-cleanup"})
+		testRegisterLocale(t, Locale{Name: "de"})
+	})
+	if LookupLocale("test-cleanup") != nil {
+		t.Errorf("Unexpected locale left in the registry")
+	}
+	if LookupLocale("de") != LocaleGerman {
+		t.Errorf("Unexpected locale: %v", LookupLocale("de"))
+	}
+}
@@ -15,7 +15,6 @@ import (
 	"github.com/fatih/color"
 
 	"github.com/mattn/go-colorable"
-	"github.com/mattn/go-isatty"
 
 	"github.com/cbehopkins/pb/v3/termutil"
 )
@@ -51,6 +50,57 @@ const (
 
 	// Round elapsed time to this precision. Defaults to time.Second.
 	TimeRound
+
+	// Counts selects how plain (non-byte) numbers are printed, see CountStyle.
+	// bar.Set(pb.Counts, pb.CountSI)
+	Counts
+
+	// ThousandsSeparator is the digit group separator used by CountGrouped. Defaults to the locale one.
+	ThousandsSeparator
+
+	// CountWidth left-pads numbers of the counters element with spaces to at least this many cells.
+	CountWidth
+
+	// Units selects the unit by registered name or *Unit, see RegisterUnit.
+	// bar.Set(pb.Units, "files")
+	Units
+
+	// RatePeriod sets the period of speed: time.Second (default), time.Minute, time.Hour or AutoRatePeriod
+	RatePeriod
+
+	// BytesPrecision sets count of digits after the decimal point for bytes. Defaults to 2.
+	BytesPrecision
+
+	// BytesTrimZeros trims trailing zeros of bytes: "1.50 KiB" becomes "1.5 KiB"
+	BytesTrimZeros
+
+	// BytesCompact prints bytes without space and unit name, like a "1.2G". Useful for narrow pool rows.
+	BytesCompact
+
+	// Localization selects the locale by registered name or *Locale, see SetDefaultLocale.
+	// bar.Set(pb.Localization, "de")
+	Localization
+
+	// DurationFormat selects how etime and rtime print durations, see DurationStyle.
+	DurationFormat
+
+	// DurationCap limits remaining time, slower ETAs are printed as "> 1 day". Defaults to 24h, <0 disables.
+	DurationCap
+
+	// Pinned bars are always visible in a Pool, even when it doesn't fit terminal height
+	Pinned
+)
+
+// CountStyle defines how plain numbers are printed by counters and speed
+type CountStyle int
+
+const (
+	// CountPlain prints numbers as is: 12345678
+	CountPlain CountStyle = iota
+	// CountSI prints numbers with SI suffixes: 12.3M
+	CountSI
+	// CountGrouped prints numbers with digit groups: 12,345,678
+	CountGrouped
 )
 
 const (
@@ -80,9 +130,7 @@ func Start64(total int64) *ProgressBar {
 }
 
 var (
-	terminalWidth    = termutil.TerminalWidth
-	isTerminal       = isatty.IsTerminal
-	isCygwinTerminal = isatty.IsCygwinTerminal
+	terminalWidth = termutil.TerminalWidth
 )
 
 // ProgressBar is the main object of bar
@@ -100,13 +148,26 @@ type ProgressBar struct {
 	startTime      time.Time
 	refreshRate    time.Duration
 	tmpl           *template.Template
+	tmpls          []responsiveTemplate
 	state          *State
 	buf            *bytes.Buffer
-	ticker         *time.Ticker
 	finish         chan struct{}
 	finished       bool
 	configured     bool
 	err            error
+	formatter      func(int64) string
+	// cells of the last line written with "\r", to clear it when the terminal shrinks
+	lastCells int
+	// idleRefreshRate is the longest interval between frames without changes
+	idleRefreshRate time.Duration
+	// wake is notified on changes, see notify
+	wake atomic.Pointer[chan struct{}]
+	// maxRefreshRate bounds refresh rate adaptation, see frameTimer
+	maxRefreshRate time.Duration
+	timer          *frameTimer
+	clock          Clock
+	terminal       termutil.Terminal
+	override       *template.Template
 }
 
 func (pb *ProgressBar) configure() {
@@ -129,10 +190,8 @@ func (pb *ProgressBar) configure() {
 		}
 	}
 	if pb.vars[Terminal] == nil {
-		if f, ok := pb.output.(*os.File); ok {
-			if isTerminal(f.Fd()) || isCygwinTerminal(f.Fd()) {
-				pb.vars[Terminal] = true
-			}
+		if pb.getTerminal().IsTerminal(pb.output) {
+			pb.vars[Terminal] = true
 		}
 	}
 	if pb.vars[ReturnSymbol] == nil {
@@ -169,28 +228,35 @@ func (pb *ProgressBar) Start() *ProgressBar {
 	pb.configure()
 	pb.finished = false
 	pb.state = nil
-	pb.startTime = time.Now()
+	pb.startTime = pb.getClock().Now()
 	if st, ok := pb.vars[Static].(bool); ok && st {
 		return pb
 	}
 	pb.finish = make(chan struct{})
-	pb.ticker = time.NewTicker(pb.refreshRate)
-	go pb.writer(pb.finish)
+	// the first frame is drawn after the refresh rate like any change
+	wake := make(chan struct{}, 1)
+	wake <- struct{}{}
+	pb.wake.Store(&wake)
+	pb.timer = newFrameTimer(pb.getClock(), pb.refreshRate, pb.maxRefreshRate, pb.idleRefreshRate)
+	go pb.writer(pb.finish, wake, pb.timer)
 	return pb
 }
 
-func (pb *ProgressBar) writer(finish chan struct{}) {
-	for {
-		select {
-		case <-pb.ticker.C:
-			pb.write(false)
-		case <-finish:
-			pb.ticker.Stop()
-			pb.write(true)
-			finish <- struct{}{}
-			return
-		}
+// writer redraws the bar on changes, see frameTimer
+func (pb *ProgressBar) writer(finish chan struct{}, wake chan struct{}, timer *frameTimer) {
+	resize := make(chan os.Signal, 1)
+	if pb.GetBool(Terminal) {
+		pb.mu.RLock()
+		term := pb.getTerminal()
+		pb.mu.RUnlock()
+		term.NotifyResize(resize)
+		defer term.StopResize(resize)
+	}
+	for timer.wait(finish, wake, resize) {
+		pb.write(false)
 	}
+	pb.write(true)
+	finish <- struct{}{}
 }
 
 // Write performs write to the output
@@ -212,7 +278,9 @@ func (pb *ProgressBar) write(finish bool) {
 			result += strings.Repeat(" ", r)
 		}
 	}
+	var clear string
 	if ret, ok := pb.Get(ReturnSymbol).(string); ok {
+		cells := CellCount(result)
 		result = ret + result
 		if finish && ret == "\r" {
 			if pb.GetBool(CleanOnFinish) {
@@ -222,18 +290,50 @@ func (pb *ProgressBar) write(finish bool) {
 				result += "\n"
 			}
 		}
+		if ret == "\r" {
+			clear = pb.clearWrapped(cells, width, finish)
+		}
 	}
 	var err error
-	if pb.GetBool(Color) {
-		_, err = pb.coutput.Write([]byte(result))
-	} else {
-		_, err = pb.nocoutput.Write([]byte(result))
+	pb.mu.RLock()
+	timer, clock := pb.timer, pb.getClock()
+	pb.mu.RUnlock()
+	start := clock.Now()
+	if clear != "" {
+		// cursor movement must pass even when colors are stripped
+		_, err = pb.coutput.Write([]byte(clear))
+	}
+	if err == nil {
+		if pb.GetBool(Color) {
+			_, err = pb.coutput.Write([]byte(result))
+		} else {
+			_, err = pb.nocoutput.Write([]byte(result))
+		}
 	}
+	timer.record(clock.Now().Sub(start), len(clear)+len(result))
 	if err != nil {
 		pb.SetErr(err)
 	}
 }
 
+// clearWrapped returns sequence clearing rows taken by the previous line when it was
+// printed at a wider terminal and wrapped, and remembers cells of the current line
+func (pb *ProgressBar) clearWrapped(cells, width int, finish bool) (clear string) {
+	if !pb.GetBool(Terminal) {
+		return
+	}
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if rows := wrappedRows(pb.lastCells, width); rows > 1 {
+		clear = fmt.Sprintf("\033[%dA\r\033[J", rows-1)
+	}
+	pb.lastCells = cells
+	if finish {
+		pb.lastCells = 0
+	}
+	return
+}
+
 // Total return current total bar value
 func (pb *ProgressBar) Total() int64 {
 	return atomic.LoadInt64(&pb.total)
@@ -242,18 +342,21 @@ func (pb *ProgressBar) Total() int64 {
 // SetTotal sets the total bar value
 func (pb *ProgressBar) SetTotal(value int64) *ProgressBar {
 	atomic.StoreInt64(&pb.total, value)
+	pb.notify()
 	return pb
 }
 
 // AddTotal adds to the total bar value
 func (pb *ProgressBar) AddTotal(value int64) *ProgressBar {
 	atomic.AddInt64(&pb.total, value)
+	pb.notify()
 	return pb
 }
 
 // SetCurrent sets the current bar value
 func (pb *ProgressBar) SetCurrent(value int64) *ProgressBar {
 	atomic.StoreInt64(&pb.current, value)
+	pb.notify()
 	return pb
 }
 
@@ -265,6 +368,7 @@ func (pb *ProgressBar) Current() int64 {
 // Add adding given int64 value to bar value
 func (pb *ProgressBar) Add64(value int64) *ProgressBar {
 	atomic.AddInt64(&pb.current, value)
+	pb.notify()
 	return pb
 }
 
@@ -286,6 +390,7 @@ func (pb *ProgressBar) Set(key, value interface{}) *ProgressBar {
 		pb.vars = make(map[any]any)
 	}
 	pb.vars[key] = value
+	pb.notify()
 	return pb
 }
 
@@ -337,10 +442,16 @@ func (pb *ProgressBar) Width() (width int) {
 	pb.mu.RLock()
 	width = pb.width
 	maxWidth := pb.maxWidth
+	term := pb.terminal
 	pb.mu.RUnlock()
 	if width <= 0 {
 		var err error
-		if width, err = terminalWidth(); err != nil {
+		if term != nil {
+			_, width, err = term.Size()
+		} else {
+			width, err = terminalWidth()
+		}
+		if err != nil {
 			return defaultBarWidth
 		}
 	}
@@ -377,10 +488,62 @@ func (pb *ProgressBar) StartTime() time.Time {
 	return pb.startTime
 }
 
+// SetFormatter sets custom function for numbers formatting
+// When set it takes precedence over Units, Bytes and Counts settings. Pass nil to reset.
+func (pb *ProgressBar) SetFormatter(f func(int64) string) *ProgressBar {
+	pb.mu.Lock()
+	pb.formatter = f
+	pb.mu.Unlock()
+	return pb
+}
+
 // Format convert int64 to string according to the current settings
-func (pb *ProgressBar) Format(v int64) string {
-	if pb.GetBool(Bytes) {
-		return formatBytes(v, pb.GetBool(SIBytesPrefix))
+func (pb *ProgressBar) Format(v int64) (s string) {
+	pb.mu.RLock()
+	f := pb.formatter
+	pb.mu.RUnlock()
+	switch {
+	case f != nil:
+		s = f(v)
+	default:
+		s = pb.unit().format(pb, v)
+	}
+	return
+}
+
+// formatCounter formats a value of the counters element, padded to CountWidth
+func (pb *ProgressBar) formatCounter(v int64) string {
+	s := pb.Format(v)
+	if w, ok := pb.Get(CountWidth).(int); ok {
+		s = padLeft(s, w)
+	}
+	return s
+}
+
+func (pb *ProgressBar) bytesFormat(si bool) bytesFormat {
+	f := bytesFormat{
+		si:        si,
+		precision: 2,
+		trim:      pb.GetBool(BytesTrimZeros),
+		compact:   pb.GetBool(BytesCompact),
+	}
+	if p, ok := pb.Get(BytesPrecision).(int); ok && p >= 0 {
+		f.precision = p
+	}
+	return f
+}
+
+func (pb *ProgressBar) formatCount(v int64) string {
+	style, _ := pb.Get(Counts).(CountStyle)
+	switch style {
+	case CountSI:
+		return pb.locale().Number(formatCountSI(v))
+	case CountGrouped:
+		sep, ok := pb.Get(ThousandsSeparator).(string)
+		if !ok {
+			sep = pb.locale().ThousandsSeparator
+		}
+		return formatCountGrouped(v, sep)
 	}
 	return strconv.FormatInt(v, 10)
 }
@@ -395,6 +558,7 @@ func (pb *ProgressBar) Finish() *ProgressBar {
 	finishChan := pb.finish
 	pb.finished = true
 	pb.mu.Unlock()
+	pb.notify()
 	if finishChan != nil {
 		finishChan <- struct{}{}
 		<-finishChan
@@ -424,6 +588,8 @@ func (pb *ProgressBar) SetTemplateString(tmpl string) *ProgressBar {
 	pb.mu.Lock()
 	defer pb.mu.Unlock()
 	pb.tmpl, pb.err = getTemplate(tmpl)
+	pb.tmpls = nil
+	pb.notify()
 	return pb
 }
 
@@ -432,6 +598,20 @@ func (pb *ProgressBar) SetTemplate(tmpl ProgressBarTemplate) *ProgressBar {
 	return pb.SetTemplateString(string(tmpl))
 }
 
+// setOverride renders the bar with tmpl instead of its own template, empty tmpl resets it
+func (pb *ProgressBar) setOverride(tmpl string) {
+	var t *template.Template
+	if tmpl != "" {
+		var err error
+		if t, err = getTemplate(tmpl); err != nil {
+			return
+		}
+	}
+	pb.mu.Lock()
+	pb.override = t
+	pb.mu.Unlock()
+}
+
 // NewProxyReader creates a wrapper for given reader, but with progress handle
 // Takes io.Reader or io.ReadCloser
 // Also, it automatically switches progress bar to handle units as bytes
@@ -449,6 +629,15 @@ func (pb *ProgressBar) NewProxyWriter(r io.Writer) *Writer {
 }
 
 func (pb *ProgressBar) render() (result string, width int) {
+	return pb.renderMode(false)
+}
+
+// measure renders the bar without changing its state and records element widths of aligned columns
+func (pb *ProgressBar) measure() {
+	pb.renderMode(true)
+}
+
+func (pb *ProgressBar) renderMode(measure bool) (result string, width int) {
 	defer func() {
 		if r := recover(); r != nil {
 			pb.SetErr(fmt.Errorf("render panic: %v", r))
@@ -462,26 +651,60 @@ func (pb *ProgressBar) render() (result string, width int) {
 		pb.state = &State{ProgressBar: pb}
 		pb.buf = bytes.NewBuffer(nil)
 	}
+	clock := pb.getClock()
 	if pb.startTime.IsZero() {
-		pb.startTime = time.Now()
+		pb.startTime = clock.Now()
+	}
+	if !measure {
+		pb.state.id++
 	}
-	pb.state.id++
 	pb.state.finished = pb.finished
-	pb.state.time = time.Now()
+	pb.state.time = clock.Now()
+	tmpls := pb.tmpls
+	if len(tmpls) == 0 {
+		tmpls = []responsiveTemplate{{t: pb.tmpl}}
+	}
+	if pb.override != nil {
+		tmpls = []responsiveTemplate{{t: pb.override}}
+	}
 	pb.mu.Unlock()
 
 	pb.state.width = pb.Width()
 	width = pb.state.width
 	pb.state.total = pb.Total()
 	pb.state.current = pb.Current()
-	pb.buf.Reset()
+	pb.state.measure = measure
+	defer func() {
+		pb.state.dry, pb.state.measure = false, false
+	}()
 
-	if e := pb.tmpl.Execute(pb.buf, pb.state); e != nil {
-		pb.SetErr(e)
-		return "", 0
+	for i, tmpl := range tmpls {
+		last := i == len(tmpls)-1
+		if !last && tmpl.minWidth > width {
+			continue
+		}
+		// rejected templates must not change the state
+		pb.state.dry = measure || !last
+		var err error
+		if result, err = pb.execute(tmpl.t); err != nil {
+			pb.SetErr(err)
+			return "", 0
+		}
+		if !last && !fitsWidth(result, pb.state.recalc, width) {
+			continue
+		}
+		if pb.state.dry && !measure {
+			pb.state.dry = false
+			if result, err = pb.execute(tmpl.t); err != nil {
+				pb.SetErr(err)
+				return "", 0
+			}
+		}
+		break
+	}
+	if measure {
+		return
 	}
-
-	result = pb.buf.String()
 
 	aec := len(pb.state.recalc)
 	if aec == 0 {
@@ -495,10 +718,15 @@ func (pb *ProgressBar) render() (result string, width int) {
 		result = strings.Replace(result, adElPlaceholder, "", -1)
 		result = StripString(result, pb.state.Width())
 	} else {
-		pb.state.adaptiveElWidth = (width - staticWidth) / aec
-		for _, el := range pb.state.recalc {
+		widths := adaptiveLayout(pb.state.recalc, width-staticWidth)
+		for i, el := range pb.state.recalc {
+			pb.state.adaptiveElWidth = widths[i]
 			result = strings.Replace(result, adElPlaceholder, el.ProgressElement(pb.state), 1)
 		}
+		if CellCount(result) > width {
+			// minimal widths don't fit
+			result = StripString(result, width)
+		}
 	}
 	pb.state.recalc = pb.state.recalc[:0]
 	return
@@ -510,6 +738,7 @@ func (pb *ProgressBar) SetErr(err error) *ProgressBar {
 	pb.mu.Lock()
 	pb.err = err
 	pb.mu.Unlock()
+	pb.notify()
 	return pb
 }
 
@@ -528,6 +757,18 @@ func (pb *ProgressBar) String() string {
 	return res
 }
 
+// execute executes the template with the bar state, pb.rm must be held
+func (pb *ProgressBar) execute(t *template.Template) (string, error) {
+	pb.buf.Reset()
+	pb.state.elIndex = 0
+	pb.state.recalc = pb.state.recalc[:0]
+	pb.state.tmpl = t
+	if err := t.Execute(pb.buf, pb.state); err != nil {
+		return "", err
+	}
+	return pb.buf.String(), nil
+}
+
 // ProgressElement implements Element interface
 func (pb *ProgressBar) ProgressElement(s *State, args ...string) string {
 	if s.IsAdaptiveWidth() {
@@ -542,12 +783,18 @@ type State struct {
 	*ProgressBar
 
 	id                     uint64
+	elIndex                int
 	total, current         int64
 	width, adaptiveElWidth int
 	finished, adaptive     bool
 	time                   time.Time
 
-	recalc []Element
+	recalc []adaptiveElement
+
+	// tmpl is the executed template, dry execution doesn't advance cycles and
+	// measure execution records element widths of aligned columns
+	tmpl         *template.Template
+	dry, measure bool
 }
 
 // Id it's the current state identifier
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/fatih/color"
+
+	"github.com/cbehopkins/pb/v3/termutil"
 )
 
 func TestPBBasic(t *testing.T) {
@@ -73,6 +75,54 @@ func TestPBWidth(t *testing.T) {
 	}
 }
 
+func TestPBTerminal(t *testing.T) {
+	term := termutil.NewFake(10, 33)
+	bar := New(0).SetWriter(bytes.NewBuffer(nil)).SetTerminal(term)
+	if a, e := bar.Width(), 33; a != e {
+		t.Errorf("Unexpected width: actual: %v; expected: %v", a, e)
+	}
+	if !bar.GetBool(Terminal) {
+		t.Errorf("Expected the writer to be a terminal")
+	}
+	term.Resize(10, 0)
+	if a, e := bar.Width(), defaultBarWidth; a != e {
+		t.Errorf("Unexpected width: actual: %v; expected: %v", a, e)
+	}
+	term = termutil.NewFake(10, 33)
+	term.SetTerminal(false)
+	bar = New(0).SetWriter(bytes.NewBuffer(nil)).SetTerminal(term)
+	if bar.GetBool(Terminal) {
+		t.Errorf("Unexpected terminal for a not terminal writer")
+	}
+}
+
+func TestPBFormat(t *testing.T) {
+	bar := new(ProgressBar)
+	for _, tc := range []struct {
+		name string
+		set  func()
+		v    int64
+		e    string
+	}{
+		{"plain", func() {}, 12345, "12345"},
+		{"si", func() { bar.Set(Counts, CountSI) }, 12345, "12.3k"},
+		{"grouped", func() { bar.Set(Counts, CountGrouped) }, 12345, "12,345"},
+		{"separator", func() { bar.Set(ThousandsSeparator, " ") }, 12345, "12 345"},
+		{"width", func() { bar.Set(CountWidth, 8) }, 42, "42"},
+		{"formatter", func() { bar.SetFormatter(func(v int64) string { return fmt.Sprintf("#%d", v) }) }, 42, "#42"},
+		{"formatter reset", func() { bar.SetFormatter(nil) }, 12345, "12 345"},
+	} {
+		tc.set()
+		if a := bar.Format(tc.v); a != tc.e {
+			t.Errorf("%s: Unexpected format: %q; want %q", tc.name, a, tc.e)
+		}
+	}
+	// CountWidth pads only counters, not speed
+	if a, e := bar.FormatSpeed(42), "42 p/s"; a != e {
+		t.Errorf("Unexpected speed: %q; want %q", a, e)
+	}
+}
+
 func TestPBMaxWidth(t *testing.T) {
 	terminalWidth = func() (int, error) {
 		return 50, nil
@@ -109,6 +159,21 @@ func TestAddTotal(t *testing.T) {
 	}
 }
 
+func TestPBFormatBytes(t *testing.T) {
+	bar := new(ProgressBar).Set(Bytes, true)
+	if a, e := bar.Format(3*_PiB/2), "1.50 PiB"; a != e {
+		t.Errorf("Unexpected format: actual: %v; expected: %v", a, e)
+	}
+	bar.Set(BytesPrecision, 3).Set(BytesTrimZeros, true)
+	if a, e := bar.Format(3*_PiB/2), "1.5 PiB"; a != e {
+		t.Errorf("Unexpected format: actual: %v; expected: %v", a, e)
+	}
+	bar.Set(BytesPrecision, 1).Set(BytesCompact, true).Set(SIBytesPrefix, true)
+	if a, e := bar.Format(1234*_MB), "1.2G"; a != e {
+		t.Errorf("Unexpected format: actual: %v; expected: %v", a, e)
+	}
+}
+
 func TestPBTemplate(t *testing.T) {
 	bar := new(ProgressBar)
 	result := bar.SetTotal(100).SetCurrent(50).SetWidth(40).String()
@@ -232,6 +297,23 @@ func TestPBFlags(t *testing.T) {
 	}
 }
 
+func TestPBClearWrapped(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	bar := ProgressBarTemplate(`{{bar . }}`).New(10).SetWriter(buf).Set(Terminal, true).Set(ReturnSymbol, "\r")
+	bar.SetWidth(20).Write()
+	buf.Reset()
+	// line of 20 cells is wrapped into 3 rows at 8 columns
+	bar.SetWidth(8).Write()
+	if a, e := buf.String(), "\033[2A\r\033[J\r[______]"; a != e {
+		t.Errorf("Unexpected output: %q; want %q", a, e)
+	}
+	buf.Reset()
+	bar.Write()
+	if a, e := buf.String(), "\r[______]"; a != e {
+		t.Errorf("Unexpected output: %q; want %q", a, e)
+	}
+}
+
 func BenchmarkRender(b *testing.B) {
 	var formats = []string{
 		string(Simple),
@@ -0,0 +1,69 @@
+package pbtest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update rewrites golden files instead of comparing: go test ./... -pbtest.update
+var update = flag.Bool("pbtest.update", false, "update golden files")
+
+// AssertScreen fails the test when visible screen contents differ from want lines
+func AssertScreen(t testing.TB, s *Screen, want ...string) {
+	t.Helper()
+	if a, e := s.String(), strings.Join(want, "\n"); a != e {
+		t.Errorf("Unexpected screen:\n%s\nwant:\n%s", a, e)
+	}
+}
+
+// AssertFrames fails the test when recorded frames differ from want, see Screen.Frames
+// Consecutive equal frames are compared once.
+func AssertFrames(t testing.TB, s *Screen, want ...string) {
+	t.Helper()
+	var frames []string
+	for _, f := range s.Frames() {
+		if len(frames) == 0 || frames[len(frames)-1] != f {
+			frames = append(frames, f)
+		}
+	}
+	if len(frames) != len(want) {
+		t.Errorf("Unexpected frames count %d, want %d:\n%s", len(frames), len(want), strings.Join(frames, "\n---\n"))
+		return
+	}
+	for i := range want {
+		if frames[i] != want[i] {
+			t.Errorf("Unexpected frame %d:\n%s\nwant:\n%s", i, frames[i], want[i])
+		}
+	}
+}
+
+// Golden compares got with testdata/<name>.golden, the file is written when -pbtest.update is set
+func Golden(t testing.TB, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Can't read golden file: %v (run with -pbtest.update to create it)", err)
+	}
+	if string(want) != got {
+		t.Errorf("Unexpected result for %s:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// GoldenScreen compares visible screen contents with a golden file, see Golden
+func GoldenScreen(t testing.TB, s *Screen, name string) {
+	t.Helper()
+	Golden(t, name, s.String()+"\n")
+}
@@ -0,0 +1,93 @@
+package pbtest
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeClock is a manually advanced clock for deterministic tests, it implements pb.Clock
+// Use it with bar.SetClock or as Pool.Clock.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+// NewFakeClock creates a clock stopped at given time
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current time of the clock
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Timer returns a channel receiving the time once the clock is advanced by d
+func (c *FakeClock) Timer(d time.Duration) (<-chan time.Time, func() bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{at: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+		return t.c, func() bool { return false }
+	}
+	c.timers = append(c.timers, t)
+	return t.c, func() bool {
+		return c.stop(t)
+	}
+}
+
+func (c *FakeClock) stop(t *fakeTimer) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, ct := range c.timers {
+		if ct == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the clock forward and fires timers that are due, in order
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].at.Before(c.timers[j].at)
+	})
+	for len(c.timers) > 0 && !c.timers[0].at.After(c.now) {
+		c.timers[0].c <- c.now
+		c.timers = c.timers[1:]
+	}
+}
+
+// Timers returns count of pending timers. Tests may wait for it to be sure
+// a renderer goroutine is waiting on the clock before advancing it.
+func (c *FakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// WaitTimers waits until at least n timers are pending, it returns false after the real timeout
+func (c *FakeClock) WaitTimers(n int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for c.Timers() < n {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
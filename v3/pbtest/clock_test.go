@@ -0,0 +1,38 @@
+package pbtest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+	t1, _ := c.Timer(2 * time.Second)
+	t2, _ := c.Timer(time.Second)
+	_, stop := c.Timer(time.Second)
+	if !stop() || c.Timers() != 2 {
+		t.Errorf("Unexpected timers: %d", c.Timers())
+	}
+	c.Advance(time.Second)
+	select {
+	case at := <-t2:
+		if !at.Equal(start.Add(time.Second)) {
+			t.Errorf("Unexpected time: %v", at)
+		}
+	default:
+		t.Errorf("Timer isn't fired")
+	}
+	select {
+	case <-t1:
+		t.Errorf("Timer is fired too early")
+	default:
+	}
+	c.Advance(time.Second)
+	if len(t1) != 1 || c.Timers() != 0 {
+		t.Errorf("Timer isn't fired")
+	}
+	if a := c.Now(); !a.Equal(start.Add(2 * time.Second)) {
+		t.Errorf("Unexpected now: %v", a)
+	}
+}
@@ -0,0 +1,380 @@
+// Package pbtest provides an in-memory terminal screen and helpers for testing rendered progress bars
+package pbtest
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Screen is an in-memory VT100 screen. It's an io.Writer, so it can be used as Pool.Output
+// or ProgressBar writer. Supported are cursor movement and positioning, erase in line and display,
+// automatic wrap and scrolling, the alternate screen buffer and cursor visibility.
+// SGR (colors) and other sequences are ignored. Like a terminal with output processing
+// enabled, "\n" moves the cursor to the beginning of the next line.
+type Screen struct {
+	mu         sync.Mutex
+	cols, rows int
+	cells      [][]rune
+	row, col   int
+	// wrap is set when the last column was written and the next rune goes to the next line
+	wrap       bool
+	scrollback []string
+	main       *screenState
+	hidden     bool
+	sync       bool
+	frames     []string
+	pending    []byte
+}
+
+// screenState is the main screen saved while the alternate one is active
+type screenState struct {
+	cells    [][]rune
+	row, col int
+}
+
+// wide rune is followed by this placeholder cell
+const wideTail = -1
+
+// NewScreen creates a screen of given size
+func NewScreen(cols, rows int) *Screen {
+	s := &Screen{cols: cols, rows: rows}
+	s.cells = s.blank()
+	return s
+}
+
+func (s *Screen) blank() [][]rune {
+	cells := make([][]rune, s.rows)
+	for i := range cells {
+		cells[i] = s.blankRow()
+	}
+	return cells
+}
+
+func (s *Screen) blankRow() []rune {
+	row := make([]rune, s.cols)
+	for i := range row {
+		row[i] = ' '
+	}
+	return row
+}
+
+// Size returns width and height of the screen
+func (s *Screen) Size() (cols, rows int) {
+	return s.cols, s.rows
+}
+
+// Write interprets p as terminal output. Escape sequences split between writes are supported.
+// Screen contents are recorded as a frame after each write, unless it ends inside
+// a synchronized output block (DEC mode 2026).
+func (s *Screen) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := append(s.pending, p...)
+	s.pending = nil
+	for i := 0; i < len(data); {
+		n := s.process(data[i:])
+		if n == 0 {
+			// incomplete sequence, wait for the rest
+			s.pending = append([]byte(nil), data[i:]...)
+			break
+		}
+		i += n
+	}
+	if !s.sync {
+		s.frames = append(s.frames, s.text())
+	}
+	return len(p), nil
+}
+
+// process handles the first rune or sequence of data, returns count of consumed bytes or 0 when incomplete
+func (s *Screen) process(data []byte) int {
+	switch data[0] {
+	case '\033':
+		return s.escape(data)
+	case '\r':
+		s.col, s.wrap = 0, false
+	case '\n':
+		s.col, s.wrap = 0, false
+		s.lineFeed()
+	case '\b':
+		if s.col > 0 {
+			s.col--
+		}
+		s.wrap = false
+	case '\t':
+		s.col = (s.col/8 + 1) * 8
+		if s.col >= s.cols {
+			s.col = s.cols - 1
+		}
+	case '\a':
+	default:
+		if !utf8.FullRune(data) {
+			return 0
+		}
+		r, size := utf8.DecodeRune(data)
+		s.put(r)
+		return size
+	}
+	return 1
+}
+
+// escape handles an escape sequence
+func (s *Screen) escape(data []byte) int {
+	if len(data) < 2 {
+		return 0
+	}
+	switch data[1] {
+	case '[':
+		for i := 2; i < len(data); i++ {
+			if c := data[i]; c >= 0x40 && c <= 0x7e {
+				s.csi(string(data[2:i]), c)
+				return i + 1
+			}
+		}
+		return 0
+	case ']':
+		// operating system command, terminated by BEL or ST
+		for i := 2; i < len(data); i++ {
+			if data[i] == '\a' {
+				return i + 1
+			}
+			if data[i] == '\033' && i+1 < len(data) && data[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return 0
+	case 'c':
+		s.cells, s.row, s.col, s.wrap = s.blank(), 0, 0, false
+	}
+	return 2
+}
+
+// csi handles a control sequence with given parameters and final byte
+func (s *Screen) csi(params string, final byte) {
+	private := strings.HasPrefix(params, "?")
+	params = strings.TrimPrefix(params, "?")
+	var args []int
+	for _, p := range strings.Split(params, ";") {
+		n, _ := strconv.Atoi(p)
+		args = append(args, n)
+	}
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+	if private {
+		s.mode(args[0], final == 'h')
+		return
+	}
+	switch final {
+	case 'A':
+		s.moveTo(s.row-arg(0, 1), s.col)
+	case 'B':
+		s.moveTo(s.row+arg(0, 1), s.col)
+	case 'C':
+		s.moveTo(s.row, s.col+arg(0, 1))
+	case 'D':
+		s.moveTo(s.row, s.col-arg(0, 1))
+	case 'E':
+		s.moveTo(s.row+arg(0, 1), 0)
+	case 'F':
+		s.moveTo(s.row-arg(0, 1), 0)
+	case 'G':
+		s.moveTo(s.row, arg(0, 1)-1)
+	case 'H', 'f':
+		s.moveTo(arg(0, 1)-1, arg(1, 1)-1)
+	case 'J':
+		s.eraseDisplay(arg(0, 0))
+	case 'K':
+		s.eraseLine(arg(0, 0))
+	}
+}
+
+// mode sets or resets a private mode
+func (s *Screen) mode(n int, set bool) {
+	switch n {
+	case 25:
+		s.hidden = !set
+	case 1049, 1047:
+		if set && s.main == nil {
+			s.main = &screenState{cells: s.cells, row: s.row, col: s.col}
+			s.cells, s.row, s.col = s.blank(), 0, 0
+		} else if !set && s.main != nil {
+			s.cells, s.row, s.col = s.main.cells, s.main.row, s.main.col
+			s.main = nil
+		}
+		s.wrap = false
+	case 2026:
+		s.sync = set
+	}
+}
+
+func (s *Screen) moveTo(row, col int) {
+	s.row, s.col, s.wrap = clamp(row, s.rows-1), clamp(col, s.cols-1), false
+}
+
+func clamp(v, max int) int {
+	if v < 0 {
+		return 0
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func (s *Screen) eraseLine(mode int) {
+	from, to := s.col, s.cols
+	switch mode {
+	case 1:
+		from, to = 0, s.col+1
+	case 2:
+		from = 0
+	}
+	for i := from; i < to && i < s.cols; i++ {
+		s.cells[s.row][i] = ' '
+	}
+}
+
+func (s *Screen) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		s.eraseLine(0)
+		for i := s.row + 1; i < s.rows; i++ {
+			s.cells[i] = s.blankRow()
+		}
+	case 1:
+		s.eraseLine(1)
+		for i := 0; i < s.row; i++ {
+			s.cells[i] = s.blankRow()
+		}
+	default:
+		s.cells = s.blank()
+	}
+}
+
+// lineFeed moves the cursor down, scrolling the screen at the bottom
+func (s *Screen) lineFeed() {
+	if s.row < s.rows-1 {
+		s.row++
+		return
+	}
+	if s.main == nil {
+		s.scrollback = append(s.scrollback, rowString(s.cells[0]))
+	}
+	s.cells = append(s.cells[1:], s.blankRow())
+}
+
+// put prints a rune at the cursor
+func (s *Screen) put(r rune) {
+	w := runewidth.RuneWidth(r)
+	if w == 0 {
+		return
+	}
+	if s.wrap || s.col+w > s.cols {
+		s.col, s.wrap = 0, false
+		s.lineFeed()
+	}
+	s.cells[s.row][s.col] = r
+	if w == 2 && s.col+1 < s.cols {
+		s.cells[s.row][s.col+1] = wideTail
+	}
+	s.col += w
+	if s.col >= s.cols {
+		s.col, s.wrap = s.cols-1, true
+	}
+}
+
+func rowString(row []rune) string {
+	var b strings.Builder
+	for _, r := range row {
+		if r != wideTail {
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// Lines returns all rows of the screen without trailing spaces
+func (s *Screen) Lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lines()
+}
+
+func (s *Screen) lines() []string {
+	lines := make([]string, len(s.cells))
+	for i, row := range s.cells {
+		lines[i] = rowString(row)
+	}
+	return lines
+}
+
+// String returns visible screen contents: rows without trailing spaces and trailing empty rows
+func (s *Screen) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.text()
+}
+
+func (s *Screen) text() string {
+	lines := s.lines()
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Frames returns screen contents recorded after each write, see Write
+func (s *Screen) Frames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.frames...)
+}
+
+// WaitFrames waits until at least n frames are recorded, it returns false after the timeout
+func (s *Screen) WaitFrames(n int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for len(s.Frames()) < n {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
+
+// Scrollback returns rows scrolled off the top of the main screen
+func (s *Screen) Scrollback() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.scrollback...)
+}
+
+// Cursor returns zero based cursor position
+func (s *Screen) Cursor() (row, col int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.row, s.col
+}
+
+// CursorVisible reports whether the cursor isn't hidden
+func (s *Screen) CursorVisible() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.hidden
+}
+
+// AltScreen reports whether the alternate screen buffer is active
+func (s *Screen) AltScreen() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.main != nil
+}
@@ -0,0 +1,80 @@
+package pbtest
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScreenCursor(t *testing.T) {
+	s := NewScreen(10, 3)
+	fmt.Fprint(s, "aaaa\nbbbb\n\033[2Acc\033[1B\033[1Cd")
+	AssertScreen(t, s, "ccaa", "bbbd")
+	fmt.Fprint(s, "\033[1;4Hx\033[3;1Hend")
+	AssertScreen(t, s, "ccax", "bbbd", "end")
+	if row, col := s.Cursor(); row != 2 || col != 3 {
+		t.Errorf("Unexpected cursor: %d, %d", row, col)
+	}
+}
+
+func TestScreenErase(t *testing.T) {
+	s := NewScreen(10, 3)
+	fmt.Fprint(s, "abcdef\nghij\nklm\033[2A\r\033[3C\033[K")
+	AssertScreen(t, s, "abc", "ghij", "klm")
+	fmt.Fprint(s, "\033[1B\033[J")
+	AssertScreen(t, s, "abc", "ghi")
+	fmt.Fprint(s, "\033[2J")
+	AssertScreen(t, s)
+}
+
+func TestScreenWrapScroll(t *testing.T) {
+	s := NewScreen(4, 2)
+	fmt.Fprint(s, "abcdef")
+	AssertScreen(t, s, "abcd", "ef")
+	fmt.Fprint(s, "\ngh")
+	AssertScreen(t, s, "ef", "gh")
+	if a := s.Scrollback(); len(a) != 1 || a[0] != "abcd" {
+		t.Errorf("Unexpected scrollback: %q", a)
+	}
+	// last column doesn't wrap until the next rune
+	s = NewScreen(4, 2)
+	fmt.Fprint(s, "abcd\r12")
+	AssertScreen(t, s, "12cd")
+}
+
+func TestScreenRunes(t *testing.T) {
+	s := NewScreen(10, 2)
+	// colors are ignored, wide runes take two cells, sequences may be split
+	for _, part := range []string{"\033[32m✓\033[0m 日本", "\033", "[2D", "\xe8", "\xaa\x9e"} {
+		fmt.Fprint(s, part)
+	}
+	AssertScreen(t, s, "✓ 日語")
+}
+
+func TestScreenModes(t *testing.T) {
+	s := NewScreen(10, 3)
+	fmt.Fprint(s, "main")
+	fmt.Fprint(s, "\033[?1049h\033[?25lalt")
+	if !s.AltScreen() || s.CursorVisible() {
+		t.Errorf("Unexpected modes: alt %v, cursor %v", s.AltScreen(), s.CursorVisible())
+	}
+	AssertScreen(t, s, "alt")
+	fmt.Fprint(s, "\033[?1049l\033[?25h")
+	AssertScreen(t, s, "main")
+	if s.AltScreen() || !s.CursorVisible() {
+		t.Errorf("Unexpected modes: alt %v, cursor %v", s.AltScreen(), s.CursorVisible())
+	}
+}
+
+func TestScreenFrames(t *testing.T) {
+	s := NewScreen(10, 3)
+	fmt.Fprint(s, "\033[?2026h1")
+	fmt.Fprint(s, "\n2\033[?2026l")
+	fmt.Fprint(s, "\r3")
+	AssertFrames(t, s, "1\n2", "1\n3")
+}
+
+func TestGolden(t *testing.T) {
+	s := NewScreen(20, 3)
+	fmt.Fprint(s, "title\n[===>____] 50%")
+	GoldenScreen(t, s, "screen")
+}
@@ -4,8 +4,13 @@
 package pb
 
 import (
+	"context"
+	"fmt"
 	"io"
+	"os"
+	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/cbehopkins/pb/v3/termutil"
@@ -31,12 +36,82 @@ func NewPool(pbs ...*ProgressBar) (pool *Pool) {
 }
 
 type Pool struct {
-	Output        io.Writer
-	RefreshRate   time.Duration
+	Output io.Writer
+	// RefreshRate is the shortest interval between frames, changes coming faster are drawn together
+	RefreshRate time.Duration
+	// IdleRefreshRate is the longest interval between frames without changes, see DefaultIdleRefreshRate.
+	// Negative value disables redraws without changes.
+	IdleRefreshRate time.Duration
+	// MaxRefreshRate is the longest interval between frames the refresh rate is lowered to when writes
+	// to Output are slow, see DefaultMaxRefreshRate. Negative value disables adaptation.
+	MaxRefreshRate time.Duration
+	// Clock drives redraws of the pool and its bars, defaults to SystemClock
+	Clock Clock
+	// Terminal is the terminal Output is drawn on, defaults to termutil.System
+	Terminal termutil.Terminal
+	// AlignColumns pads elements of all bars to a common width, so bars line up like a table
+	AlignColumns bool
+	// Header, Summary and Footer are printed above the bars, below the bars and at the very bottom.
+	// They may be static text or templates executed with PoolStats, see DefaultPoolSummary.
+	Header, Summary, Footer string
+	// Overflow selects bars visible when the pool doesn't fit terminal height, see OverflowPolicy
+	Overflow OverflowPolicy
+	// OverflowText is printed in place of hidden bars. Defaults to DefaultPoolOverflow
+	OverflowText string
+	// PersistFinished prints finished bars once above the live region and stops redrawing them
+	PersistFinished bool
+	// Sort, when set, orders bars on each redraw, e.g. ByTitle, ByPercent or ByStartTime.
+	// Pinned bars are always printed first.
+	Sort func(a, b *ProgressBar) bool
+	// AppendOnly prints bar status lines one after another instead of redrawing them.
+	// It's enabled by Start when Output isn't a terminal or the terminal can't be used.
+	AppendOnly bool
+	// AppendInterval limits how often a changing bar is printed in append-only mode, see DefaultAppendInterval
+	AppendInterval time.Duration
+	// Interactive reads keystrokes from the terminal while the pool runs, see KeyBindings and Keys.
+	// It has no effect in AppendOnly mode or when the terminal input can't be read.
+	Interactive bool
+	// KeyBindings maps keys to actions in interactive mode, defaults to DefaultKeyBindings.
+	// KeyCtrlC cancels the pool context with any bindings.
+	KeyBindings map[Key]KeyAction
+	// VerboseTemplate is used for all bars while ActionVerbose is toggled on, defaults to Full
+	VerboseTemplate ProgressBarTemplate
+	// Dashboard renders the pool full-screen on the alternate screen buffer with the cursor hidden:
+	// Header at the top, bars, a log pane with Log messages and Summary and Footer at the bottom.
+	// The original screen is restored by Stop. It has no effect when the terminal height is unknown.
+	Dashboard bool
+	// LogLines is height of the dashboard log pane, a third of the terminal when zero, negative hides it
+	LogLines int
+	// DashboardSummary prints Summary and Footer, DefaultPoolSummary when Summary is empty,
+	// to the original screen once the dashboard is closed
+	DashboardSummary bool
+	// Lifecycle defines whether the pool stops once all bars are finished or runs until Stop
+	Lifecycle     PoolLifecycle
 	bars          []*ProgressBar
+	persisted     PoolStats
+	added         bool
+	scroll        int
+	cols          columns
+	tmpls         map[string]*template.Template
 	lastBarsCount int
+	lastLines     []string
+	lastCols      int
+	appendLines   map[*ProgressBar]*appendLine
 	shutdownCh    chan struct{}
 	workerCh      chan struct{}
+	doneCh        chan struct{}
+	doneClosed    bool
+	wake          chan struct{}
+	timer         *frameTimer
+	ctx           context.Context
+	cancel        context.CancelFunc
+	input         io.Reader
+	keys          chan KeyEvent
+	paused        bool
+	verbose       bool
+	dashboard     bool
+	logs          []string
+	logPane       []string
 	m             sync.Mutex
 	finishOnce    sync.Once
 }
@@ -45,41 +120,265 @@ type Pool struct {
 func (p *Pool) Add(pbs ...*ProgressBar) {
 	p.m.Lock()
 	defer p.m.Unlock()
+	p.insert(len(p.bars), pbs)
+}
+
+// Insert adds progress bars at position i, bars at i and after are moved down.
+// Out of range positions insert at the top or the bottom.
+func (p *Pool) Insert(i int, pbs ...*ProgressBar) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	p.insert(i, pbs)
+}
+
+func (p *Pool) insert(i int, pbs []*ProgressBar) {
+	if i < 0 {
+		i = 0
+	}
+	if i > len(p.bars) {
+		i = len(p.bars)
+	}
+	wake := p.wakeChan()
 	for _, bar := range pbs {
 		bar.Set(Static, true)
+		if p.Clock != nil {
+			bar.SetClock(p.Clock)
+		}
 		bar.Start()
-		p.bars = append(p.bars, bar)
+		bar.wake.Store(&wake)
 	}
+	p.reopenDone(pbs)
+	p.notify()
+	p.added = p.added || len(pbs) > 0
+	p.bars = append(p.bars[:i], append(pbs[:len(pbs):len(pbs)], p.bars[i:]...)...)
 }
 
-// Remove removes a progress bar from the pool.
+// Remove removes a progress bar from the pool, order of other bars is kept.
 // If the bar is not found in the pool, this is a no-op.
 func (p *Pool) Remove(bar *ProgressBar) {
 	p.m.Lock()
 	defer p.m.Unlock()
+	if i := p.index(bar); i >= 0 {
+		if p.appendRunning() {
+			p.printRemoved(bar)
+		}
+		p.bars = append(p.bars[:i], p.bars[i+1:]...)
+		bar.setOverride("")
+		p.notify()
+	}
+}
+
+// index returns position of the bar in the pool or -1
+func (p *Pool) index(bar *ProgressBar) int {
 	for i, b := range p.bars {
 		if b == bar {
-			// Remove by replacing with last element and truncating
-			p.bars[i] = p.bars[len(p.bars)-1]
-			p.bars = p.bars[:len(p.bars)-1]
-			return
+			return i
 		}
 	}
+	return -1
 }
 
+// alignColumns shares column widths between given bars when AlignColumns is set.
+// Widths of all bars are measured before they are rendered, so even the first frame is aligned.
+func (p *Pool) alignColumns(bars []*ProgressBar) {
+	var cols *columns
+	if p.AlignColumns {
+		cols = &p.cols
+		cols.frame()
+	}
+	for _, bar := range bars {
+		if c, _ := bar.Get(columnsObj).(*columns); c != cols {
+			bar.Set(columnsObj, cols)
+		}
+		if cols != nil {
+			bar.measure()
+		}
+	}
+}
+
+// frame renders header, bars, summary and footer lines for the terminal of given size
+// Lines are padded to cols. Bars that overflow rows are hidden.
+// With PersistFinished, done holds lines of bars finished since the previous frame.
+func (p *Pool) frame(rows, cols int) (done, lines []string, isFinished bool) {
+	if p.PersistFinished {
+		done = p.persistFinished(cols)
+	}
+	done, p.logs = append(p.logs, done...), nil
+	if p.dashboard {
+		p.addLogPane(done)
+		done = nil
+	}
+	order := p.arrange()
+	stats := p.stats()
+	header := p.textLines(p.Header, stats)
+	footer := p.textLines(p.Summary, stats)
+	footer = append(footer, p.textLines(p.Footer, stats)...)
+
+	// a pool no bar was added to isn't finished, bars may be added later
+	isFinished = p.added
+	for _, bar := range p.bars {
+		if !bar.IsFinished() {
+			isFinished = false
+		}
+	}
+	var pane []string
+	if p.dashboard {
+		pane = p.logPaneLines(rows-len(header)-len(footer), cols)
+	}
+	bars := order
+	var overflow string
+	var overflowAt int
+	if rows > 0 {
+		// we need to hide bars that overflow terminal height
+		bars, overflow, overflowAt = p.visibleBars(order, rows-len(header)-len(footer)-len(pane), stats)
+	}
+	for _, bar := range bars {
+		bar.SetWidth(cols)
+	}
+	p.verboseBars()
+	p.alignColumns(bars)
+
+	lines = append(lines, header...)
+	for i, bar := range bars {
+		if overflow != "" && i == overflowAt {
+			lines = append(lines, overflow)
+		}
+		lines = append(lines, bar.String())
+	}
+	if overflow != "" && overflowAt == len(bars) {
+		lines = append(lines, overflow)
+	}
+	if p.dashboard {
+		// the log pane and the footer stay at the bottom of the screen
+		for len(lines) < rows-len(pane)-len(footer) {
+			lines = append(lines, "")
+		}
+	}
+	lines = append(lines, pane...)
+	lines = append(lines, footer...)
+	if p.dashboard {
+		if rows > 0 && len(lines) > rows {
+			lines = lines[:rows]
+		}
+		// wrapped lines would scroll the screen
+		for i, l := range lines {
+			lines[i] = StripString(l, cols)
+		}
+	}
+	padLines(lines, cols)
+	padLines(done, cols)
+	return
+}
+
+// persistFinished removes finished bars from the pool and returns their final render
+func (p *Pool) persistFinished(cols int) (lines []string) {
+	var bars []*ProgressBar
+	for _, bar := range p.bars {
+		if !bar.IsFinished() {
+			bars = append(bars, bar)
+			continue
+		}
+		lines = append(lines, bar.String())
+		p.persisted.add(bar)
+	}
+	p.bars = bars
+	return
+}
+
+// padLines pads lines with spaces to cols, so they overwrite previous output
+func padLines(lines []string, cols int) {
+	for i, l := range lines {
+		if r := cols - CellCount(l); r > 0 {
+			lines[i] += strings.Repeat(" ", r)
+		}
+	}
+}
+
+// Start starts the pool. When the output isn't a terminal, e.g. a pipe or a log file,
+// or the terminal can't be switched to raw mode, the pool falls back to AppendOnly mode.
 func (p *Pool) Start() (err error) {
-	p.RefreshRate = defaultRefreshRate
-	p.shutdownCh, err = termutil.RawModeOn()
-	if err != nil {
-		return
+	return p.StartContext(context.Background())
+}
+
+// StartContext starts the pool like Start. When ctx is done, the pool stops rendering,
+// restores the terminal state and marks not finished bars with ctx.Err().
+// If ctx is nil, context.Background() is used.
+func (p *Pool) StartContext(ctx context.Context) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if p.RefreshRate <= 0 {
+		p.RefreshRate = defaultRefreshRate
+	}
+	if p.Interactive {
+		ctx, p.cancel = context.WithCancel(ctx)
+	}
+	p.ctx = ctx
+	if !p.AppendOnly && p.isTerminal() {
+		if p.shutdownCh, err = p.terminal().RawModeOn(); err != nil {
+			p.AppendOnly, err = true, nil
+		}
+	} else {
+		p.AppendOnly = true
+	}
+	if p.AppendOnly {
+		p.shutdownCh = make(chan struct{})
+	} else if p.Dashboard {
+		p.dashboard = p.startDashboard()
+	}
+	if !p.AppendOnly && p.Interactive {
+		if p.input, err = p.terminal().InputOn(); err != nil {
+			// keystrokes can't be read, the pool isn't interactive
+			p.input, err = nil, nil
+		}
 	}
 	p.workerCh = make(chan struct{})
 	go p.writer()
+	if p.input != nil {
+		go p.readKeys(p.input)
+	}
+	if ctx.Done() != nil {
+		go p.watchContext(ctx)
+	}
 	return
 }
 
+// Context returns context of the started pool. In interactive mode it's canceled by ActionQuit
+// and once the pool is stopped.
+func (p *Pool) Context() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
+
+// watchContext stops the pool when ctx is done
+func (p *Pool) watchContext(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-p.workerCh:
+		return
+	}
+	p.m.Lock()
+	for _, bar := range p.bars {
+		if !bar.IsFinished() {
+			bar.SetErr(ctx.Err())
+			bar.Finish()
+		}
+	}
+	p.m.Unlock()
+	p.Stop()
+}
+
 func (p *Pool) writer() {
 	var first = true
+	defer func() {
+		if r := recover(); r != nil {
+			// restore the terminal, e.g. leave the dashboard, before crashing
+			p.terminal().RawModeOff()
+			panic(r)
+		}
+	}()
 	defer func() {
 		if first == false {
 			p.print(false)
@@ -87,25 +386,95 @@ func (p *Pool) writer() {
 			p.print(true)
 			p.print(false)
 		}
+		if p.AppendOnly {
+			p.printAppendSummary()
+		}
+		p.m.Lock()
+		p.closeDone()
 		close(p.workerCh)
+		p.m.Unlock()
 	}()
+	resize := make(chan os.Signal, 1)
+	p.terminal().NotifyResize(resize)
+	defer p.terminal().StopResize(resize)
 
-	for {
-		select {
-		case <-time.After(p.RefreshRate):
-			if p.print(first) {
-				p.print(false)
-				return
-			}
-			first = false
-		case <-p.shutdownCh:
+	timer := newFrameTimer(p.clock(), p.RefreshRate, p.MaxRefreshRate, p.IdleRefreshRate)
+	p.m.Lock()
+	p.timer = timer
+	wake := p.wakeChan()
+	p.m.Unlock()
+	for timer.wait(p.shutdownCh, wake, resize) {
+		stop := p.redraw(first)
+		// the region is drawn, the final frame must redraw it in place
+		first = false
+		if stop {
+			p.print(false)
 			return
 		}
 	}
 }
 
-// Restore terminal state and close pool
+// clock returns Clock of the pool or SystemClock
+func (p *Pool) clock() Clock {
+	if p.Clock == nil {
+		return SystemClock
+	}
+	return p.Clock
+}
+
+// terminal returns Terminal of the pool or termutil.System
+func (p *Pool) terminal() termutil.Terminal {
+	if p.Terminal == nil {
+		return termutil.System
+	}
+	return p.Terminal
+}
+
+// FrameStats returns the effective refresh rate and the last write statistics of a started pool
+func (p *Pool) FrameStats() FrameStats {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.timer.Stats()
+}
+
+// write writes a frame to the output and adapts the refresh rate to the write duration
+func (p *Pool) write(out string) {
+	start := p.clock().Now()
+	if _, err := io.WriteString(p.output(), out); err != nil {
+		// Log write errors to stderr as a fallback
+		fmt.Fprintf(os.Stderr, "pool print error: %v\n", err)
+	}
+	p.timer.record(p.clock().Now().Sub(start), len(out))
+}
+
+// wakeChan returns channel notified on changes of the pool and its bars, p.m must be held
+func (p *Pool) wakeChan() chan struct{} {
+	if p.wake == nil {
+		p.wake = make(chan struct{}, 1)
+	}
+	return p.wake
+}
+
+// notify wakes the pool writer, p.m must be held
+func (p *Pool) notify() {
+	select {
+	case p.wakeChan() <- struct{}{}:
+	default:
+	}
+}
+
+// Stop stops the pool after it prints the final frame and restores the terminal state
 func (p *Pool) Stop() error {
+	return p.Shutdown(context.Background())
+}
+
+// Shutdown stops the pool like Stop, but waits for the final frame only until ctx is done.
+// The terminal state is restored in any case, ctx.Err() is returned when the frame wasn't printed in time.
+func (p *Pool) Shutdown(ctx context.Context) (err error) {
+	if p.workerCh == nil {
+		// not started
+		return nil
+	}
 	p.finishOnce.Do(func() {
 		if p.shutdownCh != nil {
 			close(p.shutdownCh)
@@ -115,7 +484,30 @@ func (p *Pool) Stop() error {
 	// Wait for the worker to complete
 	select {
 	case <-p.workerCh:
+		p.m.Lock()
+		p.closeDone()
+		cancel := p.cancel
+		p.m.Unlock()
+		if cancel != nil {
+			// release the context derived in interactive mode from the parent one
+			cancel()
+		}
+	case <-ctx.Done():
+		err = ctx.Err()
 	}
 
-	return termutil.RawModeOff()
+	if p.AppendOnly {
+		return
+	}
+	if rerr := p.terminal().RawModeOff(); err == nil {
+		err = rerr
+	}
+	p.m.Lock()
+	dashboard := p.dashboard
+	p.dashboard = false
+	p.m.Unlock()
+	if err == nil && dashboard && p.DashboardSummary {
+		p.printAppendSummary()
+	}
+	return
 }
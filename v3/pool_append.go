@@ -0,0 +1,113 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || solaris || dragonfly || windows || plan9 || aix
+// +build linux darwin freebsd netbsd openbsd solaris dragonfly windows plan9 aix
+
+package pb
+
+import (
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultAppendInterval is how often a changing bar is printed in append-only mode
+const DefaultAppendInterval = 5 * time.Second
+
+// appendLine is the last status line printed for a bar in append-only mode
+type appendLine struct {
+	line string
+	at   time.Time
+}
+
+// output returns writer of the pool
+func (p *Pool) output() io.Writer {
+	if p.Output != nil {
+		return p.Output
+	}
+	return os.Stderr
+}
+
+// isTerminal reports whether the pool output is a terminal
+func (p *Pool) isTerminal() bool {
+	return p.terminal().IsTerminal(p.output())
+}
+
+// printAppend prints status lines of bars changed since they were printed last time,
+// but not more often than AppendInterval. Final state of a bar is always printed.
+func (p *Pool) printAppend(first bool) (isFinished bool) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	interval := p.AppendInterval
+	if interval <= 0 {
+		interval = DefaultAppendInterval
+	}
+	if p.appendLines == nil {
+		p.appendLines = make(map[*ProgressBar]*appendLine)
+	}
+	var out []string
+	if first {
+		out = append(out, p.textLines(p.Header, p.stats())...)
+	}
+	out, p.logs = append(out, p.logs...), nil
+	now := p.clock().Now()
+	isFinished = p.added
+	for _, bar := range p.bars {
+		finished := bar.IsFinished()
+		if !finished {
+			isFinished = false
+		}
+		last, ok := p.appendLines[bar]
+		if ok && !finished && now.Sub(last.at) < interval {
+			continue
+		}
+		line := appendStatus(bar)
+		if ok && line == last.line {
+			continue
+		}
+		p.appendLines[bar] = &appendLine{line: line, at: now}
+		out = append(out, line)
+	}
+	p.writeLines(out)
+	return
+}
+
+// appendRunning reports whether the pool prints in append-only mode, p.m must be held
+func (p *Pool) appendRunning() bool {
+	return p.AppendOnly && p.workerCh != nil && !p.stopped()
+}
+
+// printRemoved prints final state of a bar removed in append-only mode, unless it's printed already.
+// p.m must be held.
+func (p *Pool) printRemoved(bar *ProgressBar) {
+	line := appendStatus(bar)
+	if last, ok := p.appendLines[bar]; !ok || last.line != line {
+		p.writeLines([]string{line})
+	}
+	delete(p.appendLines, bar)
+}
+
+// appendStatus renders status line of a bar for append-only mode
+func appendStatus(bar *ProgressBar) string {
+	bar.SetWidth(defaultBarWidth)
+	return strings.TrimRight(bar.String(), " ")
+}
+
+// printAppendSummary prints summary and footer once the pool is stopped in append-only mode
+// or the dashboard is closed, DefaultPoolSummary is printed when Summary is empty
+func (p *Pool) printAppendSummary() {
+	p.m.Lock()
+	defer p.m.Unlock()
+	stats := p.stats()
+	summary := p.Summary
+	if summary == "" {
+		summary = DefaultPoolSummary
+	}
+	p.writeLines(append(p.textLines(summary, stats), p.textLines(p.Footer, stats)...))
+}
+
+func (p *Pool) writeLines(lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	p.write(strings.Join(lines, "\n") + "\n")
+}
@@ -0,0 +1,74 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || solaris || dragonfly || windows || plan9 || aix
+// +build linux darwin freebsd netbsd openbsd solaris dragonfly windows plan9 aix
+
+package pb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxLogLines is how many lines the dashboard log pane keeps
+const maxLogLines = 1000
+
+// Log prints a message above the bars like fmt.Sprint, or to the log pane in Dashboard mode
+func (p *Pool) Log(a ...any) {
+	p.log(fmt.Sprint(a...))
+}
+
+// Logf prints a message above the bars like fmt.Sprintf, or to the log pane in Dashboard mode
+func (p *Pool) Logf(format string, a ...any) {
+	p.log(fmt.Sprintf(format, a...))
+}
+
+func (p *Pool) log(msg string) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	p.logs = append(p.logs, strings.Split(strings.TrimRight(msg, "\n"), "\n")...)
+	p.notify()
+}
+
+// addLogPane appends lines to the log pane, the oldest ones are dropped
+func (p *Pool) addLogPane(lines []string) {
+	p.logPane = append(p.logPane, lines...)
+	if r := len(p.logPane) - maxLogLines; r > 0 {
+		p.logPane = append(p.logPane[:0], p.logPane[r:]...)
+	}
+}
+
+// logPaneLines returns a separator and the last lines of the log pane, rows is the space left
+// by header and footer. The pane takes LogLines rows or a third of the terminal.
+func (p *Pool) logPaneLines(rows, cols int) []string {
+	height := p.LogLines
+	if height == 0 {
+		height = rows / 3
+	}
+	if height > rows-1 {
+		height = rows - 1
+	}
+	if height <= 0 {
+		return nil
+	}
+	lines := make([]string, height+1)
+	lines[0] = strings.Repeat("─", cols)
+	logs := p.logPane
+	if len(logs) > height {
+		logs = logs[len(logs)-height:]
+	}
+	for i, l := range logs {
+		lines[height-len(logs)+1+i] = l
+	}
+	return lines
+}
+
+// startDashboard switches the output to the alternate screen, it fails when the terminal height is unknown
+// or the platform can't redraw it
+func (p *Pool) startDashboard() bool {
+	if !dashboardSupported {
+		return false
+	}
+	if rows, _, err := p.terminal().Size(); err != nil || rows <= 0 {
+		return false
+	}
+	return p.terminal().AltScreenOn(p.output()) == nil
+}
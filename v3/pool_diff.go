@@ -0,0 +1,116 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || solaris || dragonfly || windows || plan9 || aix
+// +build linux darwin freebsd netbsd openbsd solaris dragonfly windows plan9 aix
+
+package pb
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// synchronized output (DEC mode 2026), terminals without support ignore it
+	syncBegin = "\033[?2026h"
+	syncEnd   = "\033[?2026l"
+	// eraseLine clears the line from the cursor, eraseDown clears the screen below the cursor
+	eraseLine = "\033[K"
+	eraseDown = "\033[J"
+)
+
+// diffFrame returns terminal output that turns the previous live region into done and lines.
+// The cursor is expected on the row below the previous region and is left below the new one.
+// Unchanged rows are skipped by cursor movement and only the changed tail of other rows is written.
+// done rows are printed once and are not a part of the new live region.
+func diffFrame(prev, done, lines []string) string {
+	var out strings.Builder
+	out.WriteString(syncBegin)
+	if len(prev) > 0 {
+		out.WriteString("\033[" + strconv.Itoa(len(prev)) + "A")
+	}
+	var skip int
+	for i, line := range append(done[:len(done):len(done)], lines...) {
+		var cells int
+		if i < len(prev) {
+			if prev[i] == line {
+				skip++
+				continue
+			}
+			cells, line = diffLine(prev[i], line)
+		}
+		if skip > 0 {
+			out.WriteString("\033[" + strconv.Itoa(skip) + "B")
+			skip = 0
+		}
+		out.WriteString("\r")
+		if cells > 0 {
+			out.WriteString("\033[" + strconv.Itoa(cells) + "C")
+		}
+		// erase before writing, a full-width line leaves the cursor on its last cell,
+		// trailing padding is cleared by eraseLine too
+		out.WriteString(eraseLine)
+		out.WriteString(strings.TrimRight(line, " "))
+		out.WriteString("\n")
+	}
+	if skip > 0 {
+		out.WriteString("\033[" + strconv.Itoa(skip) + "B")
+	}
+	if len(done)+len(lines) < len(prev) {
+		// the region shrank, clear rest of the old one
+		out.WriteString("\r" + eraseDown)
+	}
+	out.WriteString(syncEnd)
+	return out.String()
+}
+
+// diffLine returns count of cells of the common prefix of the lines and the rest of line
+// The prefix never contains escape sequences, so cells are known exactly.
+func diffLine(prev, line string) (cells int, rest string) {
+	var n int
+	for n < len(prev) && n < len(line) {
+		r, size := utf8.DecodeRuneInString(line[n:])
+		pr, psize := utf8.DecodeRuneInString(prev[n:])
+		if r != pr || size != psize || r == '\033' {
+			break
+		}
+		n += size
+	}
+	return CellCount(line[:n]), line[n:]
+}
+
+// clearFrame returns output clearing the live region after the terminal width changed
+// Lines printed at the wider terminal may be wrapped now and take several rows each.
+func clearFrame(lines []string, cols int) string {
+	var rows int
+	for _, line := range lines {
+		rows += wrappedRows(CellCount(strings.TrimRight(line, " ")), cols)
+	}
+	if rows == 0 {
+		return ""
+	}
+	return "\033[" + strconv.Itoa(rows) + "A\r" + eraseDown
+}
+
+// diffScreen returns output redrawing changed lines of a full-screen frame at absolute positions,
+// so the screen never scrolls
+func diffScreen(prev, lines []string) string {
+	var out strings.Builder
+	out.WriteString(syncBegin)
+	for i, line := range lines {
+		var cells int
+		if i < len(prev) {
+			if prev[i] == line {
+				continue
+			}
+			cells, line = diffLine(prev[i], line)
+		}
+		// erase before writing, a full-width line leaves the cursor on its last cell
+		out.WriteString("\033[" + strconv.Itoa(i+1) + ";" + strconv.Itoa(cells+1) + "H" + eraseLine)
+		out.WriteString(strings.TrimRight(line, " "))
+	}
+	if len(lines) < len(prev) {
+		out.WriteString("\033[" + strconv.Itoa(len(lines)+1) + ";1H" + eraseDown)
+	}
+	out.WriteString(syncEnd)
+	return out.String()
+}
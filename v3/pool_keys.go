@@ -0,0 +1,178 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || solaris || dragonfly || windows || plan9 || aix
+// +build linux darwin freebsd netbsd openbsd solaris dragonfly windows plan9 aix
+
+package pb
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Key is a keystroke read in interactive mode: a character like "q" or a named key like KeyUp
+type Key string
+
+// Named keys
+const (
+	KeyUp       Key = "up"
+	KeyDown     Key = "down"
+	KeyPageUp   Key = "pgup"
+	KeyPageDown Key = "pgdown"
+	KeyEnter    Key = "enter"
+	KeyEscape   Key = "esc"
+	KeyCtrlC    Key = "ctrl+c"
+)
+
+// KeyAction is what the pool does on a key in interactive mode
+type KeyAction int
+
+const (
+	// ActionNone only delivers the key to the application
+	ActionNone KeyAction = iota
+	// ActionQuit cancels the pool context, see Pool.Context
+	ActionQuit
+	// ActionPause pauses or resumes rendering
+	ActionPause
+	// ActionScrollUp and ActionScrollDown scroll bars when they don't fit the terminal, see Pool.Scroll
+	ActionScrollUp
+	ActionScrollDown
+	// ActionVerbose toggles Pool.VerboseTemplate
+	ActionVerbose
+)
+
+// DefaultKeyBindings are key bindings of the interactive mode
+var DefaultKeyBindings = map[Key]KeyAction{
+	"q":         ActionQuit,
+	KeyCtrlC:    ActionQuit,
+	"p":         ActionPause,
+	KeyUp:       ActionScrollUp,
+	KeyDown:     ActionScrollDown,
+	KeyPageUp:   ActionScrollUp,
+	KeyPageDown: ActionScrollDown,
+	"v":         ActionVerbose,
+}
+
+// KeyEvent is a key read in interactive mode and the action it was bound to
+type KeyEvent struct {
+	Key    Key
+	Action KeyAction
+}
+
+// Keys returns channel of keys read in interactive mode.
+// Events are dropped when the application doesn't receive them in time.
+func (p *Pool) Keys() <-chan KeyEvent {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if p.keys == nil {
+		p.keys = make(chan KeyEvent, 16)
+	}
+	return p.keys
+}
+
+// Paused reports whether rendering is paused by ActionPause
+func (p *Pool) Paused() bool {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.paused
+}
+
+// readKeys reads keystrokes until r fails, RawModeOff unblocks it with io.EOF on Shutdown
+func (p *Pool) readKeys(r io.Reader) {
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		for _, key := range parseKeys(buf[:n]) {
+			p.key(key)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// key applies the action bound to the key and delivers it to the application
+func (p *Pool) key(key Key) {
+	p.m.Lock()
+	bindings := p.KeyBindings
+	if bindings == nil {
+		bindings = DefaultKeyBindings
+	}
+	action := bindings[key]
+	if key == KeyCtrlC {
+		// Ctrl-C doesn't raise SIGINT while keys are read, it always quits
+		action = ActionQuit
+	}
+	switch action {
+	case ActionPause:
+		p.paused = !p.paused
+		p.notify()
+	case ActionScrollUp:
+		p.scrollBy(1)
+	case ActionScrollDown:
+		p.scrollBy(-1)
+	case ActionVerbose:
+		p.verbose = !p.verbose
+		p.notify()
+	}
+	cancel, keys := p.cancel, p.keys
+	p.m.Unlock()
+	if action == ActionQuit && cancel != nil {
+		cancel()
+	}
+	select {
+	case keys <- KeyEvent{Key: key, Action: action}:
+	default:
+	}
+}
+
+// verboseBars sets VerboseTemplate to the pool bars while verbose mode is on and resets it otherwise
+func (p *Pool) verboseBars() {
+	var tmpl string
+	if p.verbose {
+		tmpl = string(p.VerboseTemplate)
+		if tmpl == "" {
+			tmpl = string(Full)
+		}
+	}
+	for _, bar := range p.bars {
+		bar.setOverride(tmpl)
+	}
+}
+
+// parseKeys splits terminal input into keys, unknown escape sequences are skipped
+func parseKeys(b []byte) (keys []Key) {
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == '\r' || c == '\n':
+			keys, b = append(keys, KeyEnter), b[1:]
+		case c == 0x1b && len(b) > 2 && (b[1] == '[' || b[1] == 'O'):
+			// find the final byte of the sequence
+			i := 2
+			for i < len(b) && (b[i] < 0x40 || b[i] > 0x7e) {
+				i++
+			}
+			if i == len(b) {
+				return
+			}
+			switch string(b[2 : i+1]) {
+			case "A":
+				keys = append(keys, KeyUp)
+			case "B":
+				keys = append(keys, KeyDown)
+			case "5~":
+				keys = append(keys, KeyPageUp)
+			case "6~":
+				keys = append(keys, KeyPageDown)
+			}
+			b = b[i+1:]
+		case c == 0x1b:
+			keys, b = append(keys, KeyEscape), b[1:]
+		case c < 0x20:
+			keys, b = append(keys, Key("ctrl+"+strings.ToLower(string(rune('@'+c))))), b[1:]
+		default:
+			r, n := utf8.DecodeRune(b)
+			keys, b = append(keys, Key(string(r))), b[n:]
+		}
+	}
+	return
+}
@@ -0,0 +1,84 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || solaris || dragonfly || windows || plan9 || aix
+// +build linux darwin freebsd netbsd openbsd solaris dragonfly windows plan9 aix
+
+package pb
+
+// PoolLifecycle defines when a started pool stops rendering
+type PoolLifecycle int
+
+const (
+	// AutoStop stops rendering once all bars of the pool are finished
+	AutoStop PoolLifecycle = iota
+	// RunUntilStop keeps rendering until Stop is called, so bars added later are drawn too
+	RunUntilStop
+)
+
+// Done returns a channel that's closed when all current bars of the pool are finished
+// or the pool is stopped. Adding a running bar to a pool that isn't stopped replaces the channel
+// with a new one.
+func (p *Pool) Done() <-chan struct{} {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.done()
+}
+
+// Wait blocks until all bars of the pool are finished or the pool is stopped
+func (p *Pool) Wait() {
+	<-p.Done()
+}
+
+func (p *Pool) done() chan struct{} {
+	if p.doneCh == nil {
+		p.doneCh = make(chan struct{})
+	}
+	return p.doneCh
+}
+
+// closeDone closes the Done channel, p.m must be held
+func (p *Pool) closeDone() {
+	if !p.doneClosed {
+		close(p.done())
+		p.doneClosed = true
+	}
+}
+
+// reopenDone replaces closed Done channel when a running bar is added, p.m must be held.
+// Done of a stopped pool stays closed.
+func (p *Pool) reopenDone(pbs []*ProgressBar) {
+	if !p.doneClosed || p.stopped() {
+		return
+	}
+	for _, bar := range pbs {
+		if !bar.IsFinished() {
+			p.doneCh, p.doneClosed = make(chan struct{}), false
+			return
+		}
+	}
+}
+
+// stopped reports whether the writer of the started pool has exited, p.m must be held
+func (p *Pool) stopped() bool {
+	if p.workerCh == nil {
+		return false
+	}
+	select {
+	case <-p.workerCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// redraw prints a frame and reports whether the writer should stop
+func (p *Pool) redraw(first bool) bool {
+	if p.Paused() {
+		return false
+	}
+	isFinished := p.print(first)
+	if isFinished {
+		p.m.Lock()
+		p.closeDone()
+		p.m.Unlock()
+	}
+	return isFinished && p.Lifecycle == AutoStop
+}
@@ -0,0 +1,81 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || solaris || dragonfly || windows || plan9 || aix
+// +build linux darwin freebsd netbsd openbsd solaris dragonfly windows plan9 aix
+
+package pb
+
+import "sort"
+
+// ByTitle orders bars by "title" setting
+func ByTitle(a, b *ProgressBar) bool {
+	ta, _ := a.Get("title").(string)
+	tb, _ := b.Get("title").(string)
+	return ta < tb
+}
+
+// ByPercent orders bars from the most completed one
+func ByPercent(a, b *ProgressBar) bool {
+	return a.ratio() > b.ratio()
+}
+
+// ByStartTime orders bars from the earliest started one
+func ByStartTime(a, b *ProgressBar) bool {
+	return a.StartTime().Before(b.StartTime())
+}
+
+// ratio returns completed part of the bar, zero when total is unknown
+func (pb *ProgressBar) ratio() float64 {
+	total := pb.Total()
+	if total <= 0 {
+		return 0
+	}
+	return float64(pb.Current()) / float64(total)
+}
+
+// MoveToTop moves the bar to the first position
+func (p *Pool) MoveToTop(bar *ProgressBar) {
+	p.move(bar, 0)
+}
+
+// MoveToBottom moves the bar to the last position
+func (p *Pool) MoveToBottom(bar *ProgressBar) {
+	p.move(bar, -1)
+}
+
+func (p *Pool) move(bar *ProgressBar, to int) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	i := p.index(bar)
+	if i < 0 {
+		return
+	}
+	p.bars = append(p.bars[:i], p.bars[i+1:]...)
+	if to < 0 {
+		to = len(p.bars)
+	}
+	p.bars = append(p.bars[:to], append([]*ProgressBar{bar}, p.bars[to:]...)...)
+	p.notify()
+}
+
+// Pin keeps the bar at the top of the pool and visible when the pool doesn't fit terminal height
+func (p *Pool) Pin(bar *ProgressBar) {
+	bar.Set(Pinned, true)
+}
+
+// Unpin returns the bar to the usual ordering
+func (p *Pool) Unpin(bar *ProgressBar) {
+	bar.Set(Pinned, false)
+}
+
+// arrange returns bars in display order: pinned bars first, then sorted with Sort.
+// The pool order isn't changed, so Unpin returns a bar to its place and overflow ranks bars by insertion.
+func (p *Pool) arrange() []*ProgressBar {
+	bars := append([]*ProgressBar(nil), p.bars...)
+	sort.SliceStable(bars, func(i, j int) bool {
+		a, b := bars[i], bars[j]
+		if pa, pb := a.GetBool(Pinned), b.GetBool(Pinned); pa != pb {
+			return pa
+		}
+		return p.Sort != nil && p.Sort(a, b)
+	})
+	return bars
+}
@@ -0,0 +1,122 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || solaris || dragonfly || windows || plan9 || aix
+// +build linux darwin freebsd netbsd openbsd solaris dragonfly windows plan9 aix
+
+package pb
+
+import "sort"
+
+// DefaultPoolOverflow is printed in place of bars hidden when the pool doesn't fit terminal height
+const DefaultPoolOverflow = `… and {{.Hidden}} more ({{.HiddenFinished}} finished)`
+
+// OverflowPolicy defines which bars stay visible when the pool doesn't fit terminal height
+// Bars with Pinned setting are visible with any policy.
+type OverflowPolicy int
+
+const (
+	// OverflowRecent shows most recently added bars
+	OverflowRecent OverflowPolicy = iota
+	// OverflowRunning shows running bars first, then most recently added finished ones
+	OverflowRunning
+	// OverflowOldest shows bars added first
+	OverflowOldest
+)
+
+// Scroll moves visible bars window by n bars towards less prioritized ones
+// (older bars for OverflowRecent), negative n moves it back.
+// It has effect only when the pool has more bars than terminal rows.
+func (p *Pool) Scroll(n int) {
+	p.m.Lock()
+	p.scrollBy(n)
+	p.m.Unlock()
+}
+
+// scrollBy moves visible bars window, p.m must be held
+func (p *Pool) scrollBy(n int) {
+	p.scroll += n
+	if p.scroll < 0 {
+		p.scroll = 0
+	}
+	p.notify()
+}
+
+// rankBars returns indexes of bars from the most prioritized one
+func (p *Pool) rankBars() []int {
+	rank := make([]int, len(p.bars))
+	for i := range rank {
+		rank[i] = i
+	}
+	score := func(i int) (s int) {
+		bar := p.bars[i]
+		if bar.GetBool(Pinned) {
+			s += 2
+		}
+		if p.Overflow == OverflowRunning && !bar.IsFinished() {
+			s++
+		}
+		return
+	}
+	sort.SliceStable(rank, func(a, b int) bool {
+		sa, sb := score(rank[a]), score(rank[b])
+		if sa != sb {
+			return sa > sb
+		}
+		if p.Overflow == OverflowOldest {
+			return rank[a] < rank[b]
+		}
+		return rank[a] > rank[b]
+	})
+	return rank
+}
+
+// visibleBars selects bars of display order for given count of rows, bars are ranked by insertion order
+// When not all bars fit, one row is taken by the overflow line, which is printed
+// before bars[at], in place of the first hidden bar. With no rows left only the overflow line is printed.
+func (p *Pool) visibleBars(order []*ProgressBar, rows int, stats PoolStats) (bars []*ProgressBar, overflow string, at int) {
+	if len(order) <= rows {
+		p.scroll = 0
+		return order, "", 0
+	}
+	// the overflow line is printed even when no rows are left for bars
+	slots := rows - 1
+	if slots < 0 {
+		slots = 0
+	}
+	rank := p.rankBars()
+	var pinned int
+	for pinned < len(rank) && pinned < slots && p.bars[rank[pinned]].GetBool(Pinned) {
+		pinned++
+	}
+	maxScroll := len(rank) - slots
+	if p.scroll > maxScroll {
+		p.scroll = maxScroll
+	}
+	visible := make(map[*ProgressBar]bool, slots)
+	for _, i := range rank[:pinned] {
+		visible[p.bars[i]] = true
+	}
+	for _, i := range rank[pinned+p.scroll:][:slots-pinned] {
+		visible[p.bars[i]] = true
+	}
+	at = -1
+	for _, bar := range order {
+		if visible[bar] {
+			bars = append(bars, bar)
+			continue
+		}
+		if at < 0 {
+			at = len(bars)
+		}
+		stats.Hidden++
+		if bar.IsFinished() {
+			stats.HiddenFinished++
+		}
+	}
+	text := p.OverflowText
+	if text == "" {
+		text = DefaultPoolOverflow
+	}
+	if lines := p.textLines(text, stats); len(lines) > 0 {
+		overflow = lines[0]
+	}
+	return
+}
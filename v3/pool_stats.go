@@ -0,0 +1,145 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || solaris || dragonfly || windows || plan9 || aix
+// +build linux darwin freebsd netbsd openbsd solaris dragonfly windows plan9 aix
+
+package pb
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultPoolSummary is a summary line like a "12/40 tasks done • 3 failed • 1.20 GiB/s total • ETA 4m0s"
+// pool.Summary = pb.DefaultPoolSummary
+const DefaultPoolSummary = `{{.Finished}}/{{.Bars}} tasks done` +
+	`{{if .Failed}} • {{.Failed}} failed{{end}}` +
+	`{{if .Speed}} • {{.FormatSpeed}} total{{end}}` +
+	`{{if .Remaining}} • ETA {{.FormatRemaining}}{{end}}`
+
+// PoolStats is the state of all bars of the pool
+// It's used as data for Header, Summary and Footer templates
+type PoolStats struct {
+	// Bars is count of bars in the pool
+	Bars int
+	// Finished is count of bars finished without error
+	Finished int
+	// Failed is count of bars with error, e.g. canceled ones
+	Failed int
+	// Running is count of not finished bars
+	Running int
+	// Current and Total are sums of bar values
+	Current, Total int64
+	// Speed is sum of bar speeds per second
+	Speed float64
+	// Remaining is estimated time to finish all running bars, zero when unknown
+	Remaining time.Duration
+	// Hidden is count of bars that don't fit terminal height, HiddenFinished is count of finished ones among them
+	Hidden, HiddenFinished int
+
+	// bar used for numbers formatting
+	bar *ProgressBar
+}
+
+// FormatSpeed prints total speed according to units of the first bar, like a "1.20 GiB/s"
+func (s PoolStats) FormatSpeed() string {
+	if s.bar == nil {
+		return fmt.Sprintf("%.0f p/s", s.Speed)
+	}
+	return s.bar.FormatSpeed(s.Speed)
+}
+
+// FormatRemaining prints estimated remaining time according to duration settings of the first bar
+func (s PoolStats) FormatRemaining() string {
+	if s.bar == nil {
+		return s.Remaining.String()
+	}
+	return s.bar.remainingTime(s.Remaining.Seconds())
+}
+
+// Format prints a value according to units of the first bar
+func (s PoolStats) Format(v int64) string {
+	if s.bar == nil {
+		return fmt.Sprint(v)
+	}
+	return s.bar.Format(v)
+}
+
+// speed returns last calculated speed of the bar or 0
+func (pb *ProgressBar) speed() float64 {
+	pb.rm.Lock()
+	defer pb.rm.Unlock()
+	if s, ok := pb.Get(speedObj).(*speed); ok && s.ewma != nil {
+		return s.ewma.Value()
+	}
+	return 0
+}
+
+// stats collects PoolStats of the pool bars, bars persisted by PersistFinished are included
+func (p *Pool) stats() (s PoolStats) {
+	s = p.persisted
+	var remain float64
+	for _, bar := range p.bars {
+		if s.add(bar) {
+			sp := bar.speed()
+			s.Speed += sp
+			if total, current := bar.Total(), bar.Current(); total > 0 && current < total {
+				remain += float64(total - current)
+			}
+		}
+	}
+	if s.Speed > 0 && remain > 0 {
+		s.Remaining = time.Duration(remain / s.Speed * float64(time.Second))
+	}
+	return
+}
+
+// add counts the bar, returns true when the bar is running
+func (s *PoolStats) add(bar *ProgressBar) (running bool) {
+	if s.bar == nil {
+		s.bar = bar
+	}
+	s.Bars++
+	s.Current += bar.Current()
+	s.Total += bar.Total()
+	switch {
+	case bar.Err() != nil:
+		s.Failed++
+	case bar.IsFinished():
+		s.Finished++
+	default:
+		s.Running++
+		return true
+	}
+	return false
+}
+
+// textLines executes header, summary or footer template and splits the result by lines
+func (p *Pool) textLines(text string, stats PoolStats) []string {
+	if text == "" {
+		return nil
+	}
+	t, ok := p.tmpls[text]
+	if !ok {
+		var err error
+		t, err = template.New("").Funcs(defaultTemplateFuncs).Parse(text)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "pool template error: %v\n", err)
+			t = nil
+		}
+		if p.tmpls == nil {
+			p.tmpls = make(map[string]*template.Template)
+		}
+		p.tmpls[text] = t
+	}
+	if t == nil {
+		return strings.Split(text, "\n")
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, stats); err != nil {
+		return strings.Split(text, "\n")
+	}
+	return strings.Split(buf.String(), "\n")
+}
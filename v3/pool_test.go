@@ -0,0 +1,902 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || solaris || dragonfly || plan9 || aix
+
+package pb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/VividCortex/ewma"
+
+	"github.com/cbehopkins/pb/v3/pbtest"
+	"github.com/cbehopkins/pb/v3/termutil"
+)
+
+// testPoolLines replays terminal output and returns non-empty screen rows
+func testPoolLines(out string) (lines []string) {
+	screen := pbtest.NewScreen(200, 50)
+	io.WriteString(screen, out)
+	for _, l := range screen.Lines() {
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return
+}
+
+func TestPoolAlignColumns(t *testing.T) {
+	tmpl := ProgressBarTemplate(`{{string . "title"}} {{counters . }} {{bar . }} {{percent . }}`)
+	b1 := tmpl.New(200).SetCurrent(5).Set("title", "a")
+	b2 := tmpl.New(10).SetCurrent(10).Set("title", "longer title")
+	buf := bytes.NewBuffer(nil)
+	pool := &Pool{Output: buf, AlignColumns: true}
+	pool.Add(b1, b2)
+	for _, b := range []*ProgressBar{b1, b2} {
+		b.SetMaxWidth(60)
+	}
+	// the very first frame is aligned
+	pool.print(true)
+	lines := testPoolLines(buf.String())
+	if len(lines) != 2 {
+		t.Fatalf("Unexpected lines: %q", lines)
+	}
+	want := []string{
+		"a            5 / 200 [>____________________________]   2.50%",
+		"longer title 10 / 10 [---------------------------->] 100.00%",
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("Unexpected line %d:\n%q\n%q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestPoolAlignColumnsTemplates(t *testing.T) {
+	// bars with different templates don't share columns
+	b1 := ProgressBarTemplate(`{{string . "title"}}|{{counters . }}`).New(10).Set("title", "a very long title")
+	b2 := ProgressBarTemplate(`{{counters . }}|{{string . "title"}}`).New(1000).Set("title", "b")
+	b3 := ProgressBarTemplate(`{{counters . }}|{{string . "title"}}`).New(10).Set("title", "cc")
+	screen := pbtest.NewScreen(40, 5)
+	pool := &Pool{Output: screen, AlignColumns: true}
+	pool.Add(b1, b2, b3)
+	pool.print(true)
+	pbtest.AssertScreen(t, screen, "a very long title|0 / 10", "0 / 1000|b", "  0 / 10|cc")
+
+	// measuring doesn't advance cycles
+	screen = pbtest.NewScreen(40, 5)
+	pool = &Pool{Output: screen, AlignColumns: true}
+	pool.Add(ProgressBarTemplate(`{{cycle . "1" "2" "3"}}`).New(10))
+	for i := 0; i < 3; i++ {
+		pool.print(i == 0)
+	}
+	pbtest.AssertFrames(t, screen, "1", "2", "3")
+}
+
+func TestPoolHeaderSummaryFooter(t *testing.T) {
+	tmpl := ProgressBarTemplate(`{{counters . }}`)
+	b1 := tmpl.New(100).SetCurrent(100)
+	b2 := tmpl.New(100).SetCurrent(10)
+	b3 := tmpl.New(100).SetCurrent(30)
+	buf := bytes.NewBuffer(nil)
+	pool := &Pool{
+		Output:  buf,
+		Header:  "Tasks:",
+		Summary: DefaultPoolSummary,
+		Footer:  `{{.Format .Current}} of {{.Format .Total}}`,
+	}
+	pool.Add(b1, b2, b3)
+	b1.Finish()
+	b3.SetErr(errors.New("failed")).Finish()
+	pool.print(true)
+	lines := testPoolLines(buf.String())
+	want := []string{
+		"Tasks:",
+		"100 / 100",
+		"10 / 100",
+		"30 / 100",
+		"1/3 tasks done • 1 failed",
+		"140 of 300",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("Unexpected lines: %q", lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("Unexpected line %d:\n%q\n%q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestPoolStats(t *testing.T) {
+	pool := new(Pool)
+	b1 := New(100).SetCurrent(50).Set(Bytes, true)
+	b2 := New(100).SetCurrent(50)
+	pool.Add(b1, b2)
+	for _, b := range []*ProgressBar{b1, b2} {
+		b.Set(speedObj, &speed{ewma: ewma.NewMovingAverage()})
+		b.Get(speedObj).(*speed).ewma.Add(10 * _KiB)
+	}
+	stats := pool.stats()
+	if stats.Running != 2 || stats.Current != 100 || stats.Total != 200 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if a, e := stats.FormatSpeed(), "20.00 KiB/s"; a != e {
+		t.Errorf("Unexpected speed: '%s'; want '%s'", a, e)
+	}
+	if stats.Remaining <= 0 {
+		t.Errorf("Unexpected remaining: %v", stats.Remaining)
+	}
+}
+
+func TestPoolOverflow(t *testing.T) {
+	tmpl := ProgressBarTemplate(`{{string . "title"}}`)
+	var bars []*ProgressBar
+	for _, title := range []string{"a", "b", "c", "d", "e"} {
+		bars = append(bars, tmpl.New(10).Set("title", title))
+	}
+	pool := &Pool{}
+	pool.Add(bars...)
+	bars[3].Finish()
+	inputs := []struct {
+		policy OverflowPolicy
+		pin    int
+		scroll int
+		e      []string
+	}{
+		{policy: OverflowRecent, pin: -1, e: []string{"… and 2 more (0 finished)", "c", "d", "e"}},
+		{policy: OverflowRecent, pin: -1, scroll: 1, e: []string{"… and 2 more (0 finished)", "b", "c", "d"}},
+		{policy: OverflowRecent, pin: -1, scroll: 10, e: []string{"a", "b", "c", "… and 2 more (1 finished)"}},
+		{policy: OverflowRunning, pin: -1, e: []string{"… and 2 more (1 finished)", "b", "c", "e"}},
+		{policy: OverflowOldest, pin: -1, e: []string{"a", "b", "c", "… and 2 more (1 finished)"}},
+		{policy: OverflowRecent, pin: 0, e: []string{"a", "… and 2 more (0 finished)", "d", "e"}},
+	}
+	for n, input := range inputs {
+		pool.Overflow = input.policy
+		for i, b := range bars {
+			b.Set(Pinned, i == input.pin)
+		}
+		pool.scroll = 0
+		pool.Scroll(input.scroll)
+		_, lines, _ := pool.frame(4, 30)
+		for i := range lines {
+			lines[i] = strings.TrimRight(lines[i], " ")
+		}
+		if strings.Join(lines, "|") != strings.Join(input.e, "|") {
+			t.Errorf("Unexpected lines[%d]: %q; want %q", n, lines, input.e)
+		}
+	}
+
+	// header and footer take all rows, hidden bars are still reported
+	pool.Header, pool.Footer = "header", "footer"
+	for _, rows := range []int{3, 2, 1} {
+		_, lines, _ := pool.frame(rows, 30)
+		for i := range lines {
+			lines[i] = strings.TrimRight(lines[i], " ")
+		}
+		if a, e := strings.Join(lines, "|"), "header|… and 5 more (1 finished)|footer"; a != e {
+			t.Errorf("Unexpected lines for %d rows: %q; want %q", rows, a, e)
+		}
+	}
+}
+
+func TestPoolPersistFinished(t *testing.T) {
+	tmpl := ProgressBarTemplate(`{{string . "title"}} {{counters . }}`)
+	b1 := tmpl.New(10).Set("title", "a")
+	b2 := tmpl.New(10).Set("title", "b")
+	buf := bytes.NewBuffer(nil)
+	pool := &Pool{Output: buf, PersistFinished: true, Summary: DefaultPoolSummary}
+	pool.Add(b1, b2)
+	pool.print(true)
+	b1.SetCurrent(10).Finish()
+	pool.print(false)
+	want := []string{"a 10 / 10", "b 0 / 10", "1/2 tasks done"}
+	if lines := testPoolLines(buf.String()); strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("Unexpected lines: %q; want %q", lines, want)
+	}
+	if pool.lastBarsCount != 2 {
+		t.Errorf("Unexpected live lines count: %d", pool.lastBarsCount)
+	}
+	// persisted bar is printed once
+	n := buf.Len()
+	pool.print(false)
+	if lines := testPoolLines(buf.String()); strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("Unexpected lines: %q; want %q", lines, want)
+	}
+	if strings.Contains(buf.String()[n:], "a 10") {
+		t.Errorf("Persisted bar is printed again: %q", buf.String()[n:])
+	}
+}
+
+func TestPoolOrder(t *testing.T) {
+	tmpl := ProgressBarTemplate(`{{string . "title"}}`)
+	bars := make(map[string]*ProgressBar)
+	for _, title := range []string{"a", "b", "c", "d"} {
+		bars[title] = tmpl.New(10).Set("title", title)
+	}
+	pool := &Pool{}
+	order := func() string {
+		_, lines, _ := pool.frame(0, 1)
+		return strings.Join(lines, "")
+	}
+	pool.Add(bars["a"], bars["b"], bars["c"])
+	pool.Insert(1, bars["d"])
+	if a, e := order(), "adbc"; a != e {
+		t.Errorf("Unexpected order after insert: %s; want %s", a, e)
+	}
+	pool.Remove(bars["a"])
+	if a, e := order(), "dbc"; a != e {
+		t.Errorf("Unexpected order after remove: %s; want %s", a, e)
+	}
+	pool.MoveToTop(bars["c"])
+	pool.MoveToBottom(bars["d"])
+	if a, e := order(), "cbd"; a != e {
+		t.Errorf("Unexpected order after move: %s; want %s", a, e)
+	}
+	pool.Sort = ByTitle
+	if a, e := order(), "bcd"; a != e {
+		t.Errorf("Unexpected sorted order: %s; want %s", a, e)
+	}
+	pool.Pin(bars["d"])
+	if a, e := order(), "dbc"; a != e {
+		t.Errorf("Unexpected order with pinned: %s; want %s", a, e)
+	}
+	pool.Unpin(bars["d"])
+	bars["c"].SetCurrent(5)
+	pool.Sort = ByPercent
+	if a, e := order(), "cbd"; a != e {
+		t.Errorf("Unexpected order by percent: %s; want %s", a, e)
+	}
+
+	// sorting and pinning don't change the pool order
+	pool.Sort = nil
+	pool.Pin(bars["d"])
+	if a, e := order(), "dcb"; a != e {
+		t.Errorf("Unexpected order with pinned: %s; want %s", a, e)
+	}
+	pool.Unpin(bars["d"])
+	if a, e := order(), "cbd"; a != e {
+		t.Errorf("Unexpected order after unpin: %s; want %s", a, e)
+	}
+	// overflow keeps the most recent bar in the pool order, not the sorted one
+	pool.Sort = ByTitle
+	_, lines, _ := pool.frame(2, 30)
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	if a, e := strings.Join(lines, "|"), "… and 2 more (0 finished)|d"; a != e {
+		t.Errorf("Unexpected overflow: %q; want %q", a, e)
+	}
+}
+
+func TestDiffFrame(t *testing.T) {
+	prev := []string{"a 1 / 10", "b 2 / 10", "c 3 / 10"}
+	out := diffFrame(prev, nil, []string{"a 1 / 10", "b 5 / 10"})
+	if e := "\033[?2026h\033[3A\033[1B\r\033[2C\033[K5 / 10\n\r\033[J\033[?2026l"; out != e {
+		t.Errorf("Unexpected output:\n%q\n%q", out, e)
+	}
+	lines := testPoolLines(strings.Join(prev, "\n") + "\n" + out)
+	if a, e := strings.Join(lines, "|"), "a 1 / 10|b 5 / 10"; a != e {
+		t.Errorf("Unexpected screen: %q; want %q", a, e)
+	}
+	// unchanged frame writes no text
+	if out, e := diffFrame(prev, nil, prev), "\033[?2026h\033[3A\033[3B\033[?2026l"; out != e {
+		t.Errorf("Unexpected output:\n%q\n%q", out, e)
+	}
+}
+
+func TestDiffFrameFullWidth(t *testing.T) {
+	// the last cell of a line as wide as the terminal isn't erased
+	prev := []string{"a 1 / 10  10.00%", "b 1 / 10  10.00%"}
+	lines := []string{"a 6 / 10  60.00%", "b 1 / 10  10.00%"}
+	screen := pbtest.NewScreen(16, 4)
+	io.WriteString(screen, diffFrame(nil, nil, prev)+diffFrame(prev, nil, lines))
+	pbtest.AssertScreen(t, screen, lines...)
+}
+
+func TestDiffScreen(t *testing.T) {
+	prev := []string{"a 1 / 10", "b 2 / 10", "c 3 / 10"}
+	out := diffScreen(prev, []string{"a 1 / 10", "b 5 / 10"})
+	if e := "\033[?2026h\033[2;3H\033[K5 / 10\033[3;1H\033[J\033[?2026l"; out != e {
+		t.Errorf("Unexpected output:\n%q\n%q", out, e)
+	}
+	screen := pbtest.NewScreen(20, 3)
+	io.WriteString(screen, diffScreen(nil, prev)+out)
+	pbtest.AssertScreen(t, screen, "a 1 / 10", "b 5 / 10")
+}
+
+func TestClearFrame(t *testing.T) {
+	prev := []string{strings.Repeat("a", 30), "b" + strings.Repeat(" ", 29)}
+	// first line wraps into 2 rows at 20 columns, padding of the second one isn't printed
+	if a, e := clearFrame(prev, 20), "\033[3A\r\033[J"; a != e {
+		t.Errorf("Unexpected output: %q; want %q", a, e)
+	}
+	if a, e := clearFrame(prev, 40), "\033[2A\r\033[J"; a != e {
+		t.Errorf("Unexpected output: %q; want %q", a, e)
+	}
+}
+
+func TestPoolAppendOnly(t *testing.T) {
+	tmpl := ProgressBarTemplate(`{{string . "title"}} {{counters . }}`)
+	b1 := tmpl.New(10).Set("title", "a")
+	b2 := tmpl.New(10).Set("title", "b")
+	buf := bytes.NewBuffer(nil)
+	clock := pbtest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	pool := &Pool{Output: buf, AppendOnly: true, AppendInterval: time.Second, Header: "Tasks:", Clock: clock}
+	pool.Add(b1, b2)
+	pool.print(true)
+	b2.SetCurrent(5)
+	// changes are printed not more often than AppendInterval
+	pool.print(false)
+	clock.Advance(time.Second)
+	pool.print(false)
+	// final state is printed right away
+	b1.SetCurrent(10).Finish()
+	pool.print(false)
+	pool.print(false)
+	pool.printAppendSummary()
+	want := "Tasks:\na 0 / 10\nb 0 / 10\nb 5 / 10\na 10 / 10\n1/2 tasks done\n"
+	if a := buf.String(); a != want {
+		t.Errorf("Unexpected output:\n%q\n%q", a, want)
+	}
+}
+
+func TestPoolAppendOnlyRemove(t *testing.T) {
+	tmpl := ProgressBarTemplate(`{{string . "title"}} {{counters . }}`)
+	b1 := tmpl.New(10).Set("title", "a")
+	b2 := tmpl.New(10).Set("title", "b")
+	buf := bytes.NewBuffer(nil)
+	pool := &Pool{Output: buf, AppendOnly: true, AppendInterval: time.Hour, workerCh: make(chan struct{})}
+	pool.Add(b1, b2)
+	pool.print(true)
+	// removed bar prints its final state right away
+	b1.SetCurrent(10).Finish()
+	pool.Remove(b1)
+	if _, ok := pool.appendLines[b1]; ok {
+		t.Errorf("Removed bar is kept in append state")
+	}
+	// bar removed unchanged isn't printed twice
+	pool.Remove(b2)
+	// bar removed before it's printed
+	b3 := tmpl.New(10).Set("title", "c")
+	pool.Add(b3)
+	pool.Remove(b3)
+	if a, e := buf.String(), "a 0 / 10\nb 0 / 10\na 10 / 10\nc 0 / 10\n"; a != e {
+		t.Errorf("Unexpected output:\n%q\n%q", a, e)
+	}
+	if len(pool.appendLines) != 0 {
+		t.Errorf("Unexpected append state: %v", pool.appendLines)
+	}
+}
+
+func TestPoolStartNotTerminal(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	pool := &Pool{Output: buf}
+	if err := pool.Start(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !pool.AppendOnly {
+		t.Errorf("Expected append-only mode for a buffer output")
+	}
+	pool.Add(ProgressBarTemplate(`{{counters . }}`).New(10).SetCurrent(3))
+	if err := pool.Stop(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	// DefaultPoolSummary is printed when Summary isn't set
+	if a, e := buf.String(), "3 / 10\n0/1 tasks done\n"; a != e {
+		t.Errorf("Unexpected output: %q; want %q", a, e)
+	}
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("Unexpected escape sequences: %q", buf.String())
+	}
+}
+
+func TestPoolLifecycle(t *testing.T) {
+	clock := pbtest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	// redraw waits for the writer to block on the clock and fires its timers
+	redraw := func() {
+		if !clock.WaitTimers(1, time.Second) {
+			t.Fatalf("Writer doesn't wait on the clock")
+		}
+		clock.Advance(time.Hour)
+	}
+	wait := func(ch <-chan struct{}) bool {
+		select {
+		case <-ch:
+			return true
+		case <-time.After(2 * time.Second):
+			return false
+		}
+	}
+	pool := &Pool{Output: bytes.NewBuffer(nil), Lifecycle: RunUntilStop, Clock: clock}
+	if err := pool.Start(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b1 := New(10)
+	pool.Add(b1)
+	b1.Finish()
+	redraw()
+	if !wait(pool.Done()) {
+		t.Fatalf("Done isn't closed after all bars finished")
+	}
+	// bar added later is drawn and reopens Done
+	b2 := New(10)
+	pool.Add(b2)
+	select {
+	case <-pool.Done():
+		t.Errorf("Done is closed with a running bar")
+	default:
+	}
+	b2.Finish()
+	redraw()
+	if !wait(pool.Done()) {
+		t.Fatalf("Done isn't closed after late bar finished")
+	}
+	redraw()
+	select {
+	case <-pool.workerCh:
+		t.Errorf("Pool stopped without Stop call")
+	default:
+	}
+	pool.Stop()
+
+	// auto-stop pool doesn't stop while it's empty
+	pool = &Pool{Output: bytes.NewBuffer(nil), Clock: clock}
+	pool.Start()
+	redraw()
+	// the writer is back waiting for the next frame
+	if !clock.WaitTimers(1, time.Second) {
+		t.Fatalf("Empty pool stopped")
+	}
+	select {
+	case <-pool.workerCh:
+		t.Fatalf("Empty pool stopped")
+	default:
+	}
+	b3 := New(10)
+	pool.Add(b3)
+	b3.Finish()
+	redraw()
+	if !wait(pool.workerCh) {
+		t.Errorf("Pool didn't stop after all bars finished")
+	}
+	if !wait(pool.Done()) {
+		t.Errorf("Done isn't closed after the pool stopped")
+	}
+}
+
+func TestPoolLifecycleRemoved(t *testing.T) {
+	clock := pbtest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	pool := &Pool{Output: bytes.NewBuffer(nil), Clock: clock}
+	if err := pool.Start(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// pool emptied by Remove is finished, it doesn't wait for more bars
+	bar := New(10)
+	pool.Add(bar)
+	pool.Remove(bar)
+	if !clock.WaitTimers(1, time.Second) {
+		t.Fatalf("Writer doesn't wait on the clock")
+	}
+	clock.Advance(time.Hour)
+	select {
+	case <-pool.workerCh:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Emptied pool didn't stop")
+	}
+	select {
+	case <-pool.Done():
+	default:
+		t.Errorf("Done isn't closed after the pool stopped")
+	}
+}
+
+func TestPoolLifecycleStopped(t *testing.T) {
+	clock := pbtest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	pool := &Pool{Output: bytes.NewBuffer(nil), Clock: clock}
+	if err := pool.Start(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b1 := New(10)
+	pool.Add(b1)
+	b1.Finish()
+	if !clock.WaitTimers(1, time.Second) {
+		t.Fatalf("Writer doesn't wait on the clock")
+	}
+	clock.Advance(time.Hour)
+	select {
+	case <-pool.workerCh:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Pool didn't stop after all bars finished")
+	}
+	// running bar added to the stopped pool doesn't reopen Done
+	pool.Add(New(10))
+	if err := pool.Stop(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	select {
+	case <-pool.Done():
+	default:
+		t.Errorf("Done isn't closed after Stop")
+	}
+}
+
+// blockingWriter blocks writes until release is closed
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (w blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return len(p), nil
+}
+
+func TestPoolStartContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := &Pool{Output: bytes.NewBuffer(nil), RefreshRate: time.Millisecond * 10}
+	if err := pool.StartContext(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pool.RefreshRate != time.Millisecond*10 {
+		t.Errorf("Unexpected refresh rate: %v", pool.RefreshRate)
+	}
+	bar := New(10)
+	pool.Add(bar)
+	cancel()
+	select {
+	case <-pool.workerCh:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Pool isn't stopped after context cancel")
+	}
+	if !errors.Is(bar.Err(), context.Canceled) || !bar.IsFinished() {
+		t.Errorf("Unexpected bar state: %v, finished: %v", bar.Err(), bar.IsFinished())
+	}
+	if err := pool.Stop(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestPoolStartContextNil(t *testing.T) {
+	pool := &Pool{Output: bytes.NewBuffer(nil)}
+	if err := pool.StartContext(nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pool.Context() == nil || pool.Context().Done() != nil {
+		t.Errorf("Unexpected context: %v", pool.Context())
+	}
+	if err := pool.Stop(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestPoolShutdown(t *testing.T) {
+	w := blockingWriter{release: make(chan struct{})}
+	defer close(w.release)
+	pool := &Pool{Output: w}
+	pool.Start()
+	pool.Add(New(10))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := pool.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	// not started pool
+	if err := new(Pool).Shutdown(ctx); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestPoolScreenFrames(t *testing.T) {
+	screen := pbtest.NewScreen(40, 10)
+	tmpl := ProgressBarTemplate(`{{string . "title"}} {{counters . }}`)
+	b1 := tmpl.New(10).Set("title", "a")
+	b2 := tmpl.New(10).Set("title", "b")
+	pool := &Pool{Output: screen}
+	pool.Add(b1, b2)
+	pool.print(true)
+	b2.SetCurrent(5)
+	pool.print(false)
+	pool.Remove(b1)
+	pool.print(false)
+	pbtest.AssertFrames(t, screen,
+		"a 0 / 10\nb 0 / 10",
+		"a 0 / 10\nb 5 / 10",
+		"b 5 / 10",
+	)
+}
+
+func TestPoolTerminal(t *testing.T) {
+	term := termutil.NewFake(3, 40)
+	screen := pbtest.NewScreen(40, 5)
+	pool := &Pool{Output: screen, Terminal: term, RefreshRate: time.Millisecond}
+	if err := pool.Start(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pool.AppendOnly {
+		t.Errorf("Unexpected append-only mode on a fake terminal")
+	}
+	if !term.IsRaw() {
+		t.Errorf("Expected raw mode on while the pool is running")
+	}
+	tmpl := ProgressBarTemplate(`{{string . "title"}} {{counters . }}`)
+	bars := []*ProgressBar{tmpl.New(10).Set("title", "a"), tmpl.New(10).Set("title", "b"), tmpl.New(10).Set("title", "c"), tmpl.New(10).Set("title", "d")}
+	pool.Add(bars...)
+	for _, bar := range bars {
+		bar.Finish()
+	}
+	pool.Wait()
+	if err := pool.Stop(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if term.IsRaw() {
+		t.Errorf("Expected raw mode off after Stop")
+	}
+	pbtest.AssertScreen(t, screen, "… and 2 more (2 finished)\nc 0 / 10\nd 0 / 10")
+}
+
+func TestPoolTerminalFallback(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		setup func(term *termutil.Fake)
+	}{
+		{"not a terminal", func(term *termutil.Fake) { term.SetTerminal(false) }},
+		{"raw mode error", func(term *termutil.Fake) { term.SetRawModeError(errors.New("no tty")) }},
+	} {
+		term := termutil.NewFake(10, 40)
+		tc.setup(term)
+		pool := &Pool{Output: bytes.NewBuffer(nil), Terminal: term}
+		if err := pool.Start(); err != nil {
+			t.Fatalf("%s: Unexpected error: %v", tc.name, err)
+		}
+		if !pool.AppendOnly {
+			t.Errorf("%s: Expected append-only mode", tc.name)
+		}
+		pool.Stop()
+		if term.IsRaw() {
+			t.Errorf("%s: Unexpected raw mode", tc.name)
+		}
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		keys []Key
+	}{
+		{"q", []Key{"q"}},
+		{"pv", []Key{"p", "v"}},
+		{"\x03", []Key{KeyCtrlC}},
+		{"\r", []Key{KeyEnter}},
+		{"\033[A\033[B", []Key{KeyUp, KeyDown}},
+		{"\033OA", []Key{KeyUp}},
+		{"\033[5~\033[6~", []Key{KeyPageUp, KeyPageDown}},
+		{"\033[1;5Cx", []Key{"x"}},
+		{"\033", []Key{KeyEscape}},
+		{"é", []Key{"é"}},
+	} {
+		keys := parseKeys([]byte(tc.in))
+		if fmt.Sprint(keys) != fmt.Sprint(tc.keys) {
+			t.Errorf("Unexpected keys of %q: %q; want %q", tc.in, keys, tc.keys)
+		}
+	}
+}
+
+// testDrawFrame fires timers of the pool writer waiting on the clock and waits
+// until the frame is drawn and the writer waits for the next one
+func testDrawFrame(t *testing.T, clock *pbtest.FakeClock) {
+	t.Helper()
+	if !clock.WaitTimers(1, time.Second) {
+		t.Fatalf("Writer doesn't wait on the clock")
+	}
+	clock.Advance(time.Hour)
+	if !clock.WaitTimers(1, time.Second) {
+		t.Fatalf("Writer doesn't wait for the next frame")
+	}
+}
+
+func TestPoolInteractive(t *testing.T) {
+	clock := pbtest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	term := termutil.NewFake(3, 40)
+	screen := pbtest.NewScreen(40, 5)
+	pool := &Pool{Output: screen, Terminal: term, Interactive: true, Clock: clock,
+		VerboseTemplate: `{{string . "title"}} verbose`}
+	tmpl := ProgressBarTemplate(`{{string . "title"}} {{counters . }}`)
+	bars := []*ProgressBar{tmpl.New(10).Set("title", "a"), tmpl.New(10).Set("title", "b"), tmpl.New(10).Set("title", "c")}
+	pool.Add(bars...)
+	keys := pool.Keys()
+	if err := pool.Start(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !term.IsInput() {
+		t.Fatalf("Expected keystrokes to be read")
+	}
+	event := func(in string, e KeyEvent) {
+		t.Helper()
+		term.Type(in)
+		if a := <-keys; a != e {
+			t.Errorf("Unexpected event of %q: %v; want %v", in, a, e)
+		}
+	}
+
+	event("p", KeyEvent{Key: "p", Action: ActionPause})
+	if !pool.Paused() {
+		t.Errorf("Expected paused rendering")
+	}
+	event("p", KeyEvent{Key: "p", Action: ActionPause})
+	if pool.Paused() {
+		t.Errorf("Expected resumed rendering")
+	}
+
+	event("x", KeyEvent{Key: "x"})
+	event("\033[A", KeyEvent{Key: KeyUp, Action: ActionScrollUp})
+	event("v", KeyEvent{Key: "v", Action: ActionVerbose})
+	testDrawFrame(t, clock)
+	pbtest.AssertScreen(t, screen, "a verbose", "b verbose", "c verbose")
+
+	event("q", KeyEvent{Key: "q", Action: ActionQuit})
+	pool.Wait()
+	if err := pool.Context().Err(); err != context.Canceled {
+		t.Errorf("Unexpected context error: %v", err)
+	}
+	for _, bar := range bars {
+		if bar.Err() != context.Canceled {
+			t.Errorf("Unexpected bar error: %v", bar.Err())
+		}
+	}
+	pool.Stop()
+	if term.IsRaw() || term.IsInput() {
+		t.Errorf("Expected the terminal to be restored")
+	}
+}
+
+func TestPoolInteractiveStop(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := &Pool{Output: bytes.NewBuffer(nil), Terminal: termutil.NewFake(3, 40), Interactive: true}
+	if err := pool.StartContext(parent); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := pool.Stop(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	// the derived context is released, the parent one isn't canceled
+	if err := pool.Context().Err(); err != context.Canceled {
+		t.Errorf("Unexpected context error: %v", err)
+	}
+	if err := parent.Err(); err != nil {
+		t.Errorf("Unexpected parent context error: %v", err)
+	}
+}
+
+func TestPoolKeyCtrlC(t *testing.T) {
+	pool := &Pool{KeyBindings: map[Key]KeyAction{"x": ActionNone}}
+	pool.ctx, pool.cancel = context.WithCancel(context.Background())
+	keys := pool.Keys()
+	// Ctrl-C quits with custom bindings
+	pool.key(KeyCtrlC)
+	if e := <-keys; e != (KeyEvent{Key: KeyCtrlC, Action: ActionQuit}) {
+		t.Errorf("Unexpected event: %v", e)
+	}
+	if err := pool.Context().Err(); err != context.Canceled {
+		t.Errorf("Unexpected context error: %v", err)
+	}
+}
+
+func TestPoolDashboardTruncate(t *testing.T) {
+	long := strings.Repeat("x", 30)
+	pool := &Pool{Header: long, Footer: long, LogLines: 2, dashboard: true}
+	pool.Add(ProgressBarTemplate(`{{counters . }}`).New(10))
+	pool.Log(long)
+	_, lines, _ := pool.frame(8, 20)
+	if len(lines) != 8 {
+		t.Errorf("Unexpected lines count: %d", len(lines))
+	}
+	// header, log pane and footer lines are cut to the width, so they don't wrap
+	for _, l := range lines {
+		if CellCount(l) != 20 {
+			t.Errorf("Unexpected line: %q", l)
+		}
+	}
+}
+
+func TestPoolVerboseReset(t *testing.T) {
+	tmpl := ProgressBarTemplate(`{{string . "title"}} {{counters . }}`)
+	pool := &Pool{VerboseTemplate: `{{string . "title"}} verbose`}
+	bars := []*ProgressBar{tmpl.New(10).Set("title", "a"), tmpl.New(10).Set("title", "b"), tmpl.New(10).Set("title", "c")}
+	pool.Add(bars...)
+	pool.verbose = true
+	pool.frame(10, 40)
+	// verbose mode is reset for bars hidden by overflow too
+	pool.verbose = false
+	pool.frame(2, 40)
+	for _, bar := range bars {
+		if s := bar.String(); strings.Contains(s, "verbose") {
+			t.Errorf("Unexpected bar: %q", s)
+		}
+	}
+	// removed bar is reset too
+	pool.verbose = true
+	pool.frame(10, 40)
+	pool.Remove(bars[0])
+	if s := bars[0].String(); strings.Contains(s, "verbose") {
+		t.Errorf("Unexpected removed bar: %q", s)
+	}
+}
+
+func TestPoolDashboard(t *testing.T) {
+	clock := pbtest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	term := termutil.NewFake(8, 30)
+	screen := pbtest.NewScreen(30, 8)
+	io.WriteString(screen, "$ run\n")
+	pool := &Pool{Output: screen, Terminal: term, Dashboard: true, DashboardSummary: true, LogLines: 2,
+		Clock: clock, Header: "Title", Summary: DefaultPoolSummary}
+	tmpl := ProgressBarTemplate(`{{string . "title"}} {{counters . }}`)
+	a, b := tmpl.New(10).Set("title", "a"), tmpl.New(10).Set("title", "b")
+	pool.Add(a, b)
+	if err := pool.Start(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !screen.AltScreen() || screen.CursorVisible() {
+		t.Errorf("Expected the alternate screen with the cursor hidden")
+	}
+	pool.Log("started")
+	testDrawFrame(t, clock)
+	pbtest.AssertScreen(t, screen,
+		"Title",
+		"a 0 / 10",
+		"b 0 / 10",
+		"",
+		strings.Repeat("─", 30),
+		"",
+		"started",
+		"0/2 tasks done",
+	)
+	a.SetCurrent(10).Finish()
+	b.SetCurrent(10).Finish()
+	// the last frame stops the writer
+	if !clock.WaitTimers(1, time.Second) {
+		t.Fatalf("Writer doesn't wait on the clock")
+	}
+	clock.Advance(time.Hour)
+	pool.Wait()
+	// concurrent stops, e.g. by a canceled context and by the application, print the summary once
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := pool.Stop(); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if screen.AltScreen() || !screen.CursorVisible() || term.IsAltScreen() {
+		t.Errorf("Expected the original screen to be restored")
+	}
+	pbtest.AssertScreen(t, screen, "$ run", "2/2 tasks done")
+}
+
+func TestPoolLog(t *testing.T) {
+	screen := pbtest.NewScreen(40, 10)
+	pool := &Pool{Output: screen}
+	pool.Add(ProgressBarTemplate(`{{counters . }}`).New(10))
+	pool.print(true)
+	pool.Logf("copied %d files", 3)
+	pool.print(false)
+	pbtest.AssertScreen(t, screen, "copied 3 files", "0 / 10")
+
+	buf := bytes.NewBuffer(nil)
+	pool = &Pool{Output: buf, AppendOnly: true}
+	pool.Log("first\nsecond")
+	pool.print(true)
+	if a, e := buf.String(), "first\nsecond\n"; a != e {
+		t.Errorf("Unexpected output: %q; want %q", a, e)
+	}
+}
@@ -6,12 +6,18 @@ package pb
 import (
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/cbehopkins/pb/v3/termutil"
 )
 
+// dashboardSupported reports whether print can render the Dashboard mode,
+// the console is redrawn without the terminal height here
+const dashboardSupported = false
+
 func (p *Pool) print(first bool) bool {
+	if p.AppendOnly {
+		return p.printAppend(first)
+	}
 	p.m.Lock()
 	defer p.m.Unlock()
 	var out string
@@ -34,31 +40,15 @@ func (p *Pool) print(first bool) bool {
 			}
 		}
 	}
-	cols, err := termutil.TerminalWidth()
+	_, cols, err := p.terminal().Size()
 	if err != nil {
 		cols = defaultBarWidth
 	}
-	isFinished := true
-	for _, bar := range p.bars {
-		if !bar.IsFinished() {
-			isFinished = false
-		}
-		result := bar.String()
-		if r := cols - CellCount(result); r > 0 {
-			result += strings.Repeat(" ", r)
-		}
-		out += fmt.Sprintf("\r%s\n", result)
-	}
-	var printErr error
-	if p.Output != nil {
-		_, printErr = fmt.Fprint(p.Output, out)
-	} else {
-		_, printErr = fmt.Fprint(os.Stderr, out)
-	}
-	if printErr != nil {
-		// Log write errors to stderr as a fallback
-		fmt.Fprintf(os.Stderr, "pool print error: %v\n", printErr)
+	done, lines, isFinished := p.frame(0, cols)
+	for _, line := range append(done, lines...) {
+		out += fmt.Sprintf("\r%s\n", line)
 	}
-	p.lastBarsCount = len(p.bars)
+	p.write(out)
+	p.lastBarsCount = len(lines)
 	return isFinished
 }
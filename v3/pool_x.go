@@ -3,52 +3,53 @@
 
 package pb
 
-import (
-	"fmt"
-	"os"
-	"strings"
-
-	"github.com/cbehopkins/pb/v3/termutil"
-)
+// dashboardSupported reports whether print can render the Dashboard mode
+const dashboardSupported = true
 
 func (p *Pool) print(first bool) bool {
+	if p.AppendOnly {
+		return p.printAppend(first)
+	}
 	p.m.Lock()
 	defer p.m.Unlock()
-	var out string
-	if !first {
-		out = fmt.Sprintf("\033[%dA", p.lastBarsCount)
+	if first {
+		p.lastLines = nil
 	}
-	isFinished := true
-	bars := p.bars
-	rows, cols, err := termutil.TerminalSize()
+	rows, cols, err := p.terminal().Size()
 	if err != nil {
 		cols = defaultBarWidth
 	}
-	if rows > 0 && len(bars) > rows {
-		// we need to hide bars that overflow terminal height
-		bars = bars[len(bars)-rows:]
+	if p.dashboard {
+		return p.printDashboard(rows, cols)
 	}
-	for _, bar := range bars {
-		if !bar.IsFinished() {
-			isFinished = false
-		}
-		bar.SetWidth(cols)
-		result := bar.String()
-		if r := cols - CellCount(result); r > 0 {
-			result += strings.Repeat(" ", r)
-		}
-		out += fmt.Sprintf("\r%s\n", result)
-	}
-	var printErr error
-	if p.Output != nil {
-		_, printErr = fmt.Fprint(p.Output, out)
-	} else {
-		_, printErr = fmt.Fprint(os.Stderr, out)
+	var out string
+	if p.lastLines != nil && cols != p.lastCols {
+		// the terminal was resized, redraw the whole region
+		out = clearFrame(p.lastLines, cols)
+		p.lastLines = nil
 	}
-	if printErr != nil {
-		// Log write errors to stderr as a fallback
-		fmt.Fprintf(os.Stderr, "pool print error: %v\n", printErr)
+	p.lastCols = cols
+	done, lines, isFinished := p.frame(rows, cols)
+	out += diffFrame(p.lastLines, done, lines)
+	p.write(out)
+	p.lastLines = lines
+	p.lastBarsCount = len(lines)
+	return isFinished
+}
+
+// printDashboard redraws changed lines of the full-screen dashboard, p.m must be held
+func (p *Pool) printDashboard(rows, cols int) bool {
+	var out string
+	if cols != p.lastCols {
+		// the terminal was resized, redraw the whole screen
+		out = "\033[H" + eraseDown
+		p.lastLines = nil
 	}
-	p.lastBarsCount = len(bars)
+	p.lastCols = cols
+	_, lines, isFinished := p.frame(rows, cols)
+	out += diffScreen(p.lastLines, lines)
+	p.write(out)
+	p.lastLines = lines
+	p.lastBarsCount = len(lines)
 	return isFinished
 }
@@ -1,5 +1,10 @@
 package pb
 
+import (
+	"fmt"
+	"strings"
+)
+
 var (
 	// Full - preset with all default available elements
 	// Example: 'Prefix 20/100 [-->______] 20% 1 p/s ETA 1m Suffix'
@@ -12,4 +17,23 @@ var (
 	// Simple - preset without speed and any timers. Only counters, bar and percents
 	// Example: 'Prefix 20/100 [-->______] 20% Suffix'
 	Simple ProgressBarTemplate = `{{with string . "prefix"}}{{.}} {{end}}{{counters . }} {{bar . }} {{percent . }}{{with string . "suffix"}} {{.}}{{end}}`
+
+	// Done - preset for finished bars, e.g. lingering in a pool
+	// Example: '✓ Title 100/100 1m2s'
+	Done ProgressBarTemplate = `{{green "✓"}} {{with string . "title"}}{{.}} {{end}}{{counters . }} {{etime . }}`
 )
+
+// ResponsiveFull - Full preset which drops speed, time and bar on narrow terminals instead of clipping
+// Use as follows: bar.SetResponsiveTemplates(pb.ResponsiveFull...)
+var ResponsiveFull = []ResponsiveTemplate{
+	{Template: barMinWidth(Full, 10)},
+	{Template: barMinWidth(Default, 10)},
+	{Template: barMinWidth(Simple, 10)},
+	{Template: `{{with string . "prefix"}}{{.}} {{end}}{{counters . }} {{percent . }}`},
+	{Template: `{{percent . }}`},
+}
+
+// barMinWidth makes template fall back to the next one, when bar can't be at least w cells wide
+func barMinWidth(t ProgressBarTemplate, w int) ProgressBarTemplate {
+	return ProgressBarTemplate(strings.Replace(string(t), "{{bar . }}", fmt.Sprintf("{{bar . | minwidth . %d}}", w), 1))
+}
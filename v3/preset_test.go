@@ -27,3 +27,24 @@ func TestPreset(t *testing.T) {
 		}
 	}
 }
+
+func TestResponsiveFull(t *testing.T) {
+	bar := New(100).SetCurrent(20).SetResponsiveTemplates(ResponsiveFull...)
+	for _, input := range []struct {
+		width int
+		e     string
+	}{
+		{width: 60, e: "20 / 100 [------>___________________________] 20.00% ? p/s ?"},
+		{width: 34, e: "20 / 100 [->______] 20.00% ? p/s ?"},
+		{width: 33, e: "20 / 100 [->_______] 20.00% ? p/s"},
+		{width: 30, e: "20 / 100 [-->_________] 20.00%"},
+		{width: 24, e: "20 / 100 20.00%"},
+		{width: 16, e: "20 / 100 20.00%"},
+		{width: 8, e: "20.00%"},
+		{width: 4, e: "20.00%"},
+	} {
+		if a := bar.SetWidth(input.width).String(); a != input.e {
+			t.Errorf("Unexpected result for width %d: '%s'; want '%s'", input.width, a, input.e)
+		}
+	}
+}
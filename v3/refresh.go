@@ -0,0 +1,168 @@
+package pb
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultIdleRefreshRate is the longest interval between frames of an unchanged bar or pool,
+	// so elapsed time, speed and ETA keep updating
+	DefaultIdleRefreshRate = time.Second
+	// DefaultMaxRefreshRate is the longest interval between frames the refresh rate is lowered to
+	// when writes to the output are slow
+	DefaultMaxRefreshRate = 2 * time.Second
+)
+
+// FrameStats describes rendering of a bar or a pool, for diagnostics
+type FrameStats struct {
+	// RefreshRate is the effective shortest interval between frames, adapted to write latency
+	RefreshRate time.Duration
+	// WriteDuration and Bytes are duration and size of the last frame write
+	WriteDuration time.Duration
+	Bytes         int
+}
+
+// frameTimer schedules redraws on change notifications, but not more often than the effective
+// refresh rate, and at least once per idle interval when idle is positive.
+// The effective rate is lowered from min up to max when writes take a large part of a frame interval.
+type frameTimer struct {
+	min, max, idle time.Duration
+	last           time.Time
+	clock          Clock
+
+	mu    sync.Mutex
+	stats FrameStats
+}
+
+func newFrameTimer(clock Clock, min, max, idle time.Duration) *frameTimer {
+	if idle == 0 {
+		idle = DefaultIdleRefreshRate
+	}
+	if max == 0 {
+		max = DefaultMaxRefreshRate
+	}
+	if max < min {
+		// adaptation is disabled
+		max = min
+	}
+	return &frameTimer{min: min, max: max, idle: idle, clock: clock, last: clock.Now(), stats: FrameStats{RefreshRate: min}}
+}
+
+// rate returns the effective refresh rate
+func (t *frameTimer) rate() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats.RefreshRate
+}
+
+// Stats returns a copy of frame statistics
+func (t *frameTimer) Stats() FrameStats {
+	if t == nil {
+		return FrameStats{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+// record adapts the effective refresh rate to the duration of a frame write.
+// Writes are kept within a quarter of the frame interval: a slow write lowers the rate at once,
+// fast writes raise it back gradually.
+func (t *frameTimer) record(d time.Duration, bytes int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.WriteDuration, t.stats.Bytes = d, bytes
+	rate, target := t.stats.RefreshRate, 4*d
+	switch {
+	case target > rate:
+		rate = target
+	case target < rate/2:
+		rate -= rate / 4
+	}
+	if rate > t.max {
+		rate = t.max
+	}
+	if rate < t.min {
+		rate = t.min
+	}
+	t.stats.RefreshRate = rate
+}
+
+// wait blocks until the next frame is due, it returns false when stop is closed or signaled
+// A resize is redrawn right away.
+func (t *frameTimer) wait(stop, wake <-chan struct{}, resize <-chan os.Signal) bool {
+	defer func() {
+		t.last = t.clock.Now()
+	}()
+	rate := t.rate()
+	var idle <-chan time.Time
+	if t.idle > 0 {
+		d := t.idle
+		if d < rate {
+			d = rate
+		}
+		c, stopTimer := t.clock.Timer(t.last.Add(d).Sub(t.clock.Now()))
+		defer stopTimer()
+		idle = c
+	}
+	select {
+	case <-stop:
+		return false
+	case <-resize:
+		return true
+	case <-idle:
+		return true
+	case <-wake:
+	}
+	// coalesce changes coming faster than the refresh rate
+	if d := t.last.Add(rate).Sub(t.clock.Now()); d > 0 {
+		c, stopTimer := t.clock.Timer(d)
+		defer stopTimer()
+		select {
+		case <-stop:
+			return false
+		case <-c:
+		}
+	}
+	return true
+}
+
+// notify wakes the renderer of the bar: its own writer or the pool it belongs to
+func (pb *ProgressBar) notify() {
+	if ch := pb.wake.Load(); ch != nil {
+		select {
+		case *ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// SetIdleRefreshRate sets the longest interval between frames without changes,
+// see DefaultIdleRefreshRate. Negative value disables redraws without changes.
+func (pb *ProgressBar) SetIdleRefreshRate(dur time.Duration) *ProgressBar {
+	pb.mu.Lock()
+	pb.idleRefreshRate = dur
+	pb.mu.Unlock()
+	return pb
+}
+
+// SetMaxRefreshRate sets the longest interval between frames the refresh rate is lowered to
+// when writes are slow, see DefaultMaxRefreshRate. Negative value disables adaptation.
+func (pb *ProgressBar) SetMaxRefreshRate(dur time.Duration) *ProgressBar {
+	pb.mu.Lock()
+	pb.maxRefreshRate = dur
+	pb.mu.Unlock()
+	return pb
+}
+
+// FrameStats returns the effective refresh rate and the last write statistics of a started bar
+func (pb *ProgressBar) FrameStats() FrameStats {
+	pb.mu.RLock()
+	defer pb.mu.RUnlock()
+	return pb.timer.Stats()
+}
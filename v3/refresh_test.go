@@ -0,0 +1,130 @@
+package pb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cbehopkins/pb/v3/pbtest"
+)
+
+func TestFrameTimer(t *testing.T) {
+	clock := pbtest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	timer := newFrameTimer(clock, time.Second, 0, 5*time.Second)
+	wake, stop := make(chan struct{}, 1), make(chan struct{})
+	done := make(chan bool)
+	wait := func() {
+		go func() {
+			done <- timer.wait(stop, wake, nil)
+		}()
+		clock.WaitTimers(1, time.Second)
+	}
+	// change is drawn after the refresh rate
+	wake <- struct{}{}
+	wait()
+	clock.Advance(time.Second)
+	<-done
+	// unchanged frame is drawn after the idle interval
+	wait()
+	clock.Advance(4 * time.Second)
+	if clock.Timers() != 1 {
+		t.Fatalf("Frame is drawn before idle interval")
+	}
+	clock.Advance(time.Second)
+	<-done
+}
+
+func TestPBNotify(t *testing.T) {
+	bar := New(10)
+	wake := make(chan struct{}, 1)
+	bar.wake.Store(&wake)
+	for _, f := range []func(){
+		func() { bar.Increment() },
+		func() { bar.SetCurrent(5) },
+		func() { bar.SetTotal(20) },
+		func() { bar.Set("prefix", "a") },
+	} {
+		f()
+		select {
+		case <-wake:
+		default:
+			t.Errorf("Change isn't notified")
+		}
+	}
+	// notifications are coalesced and never block
+	bar.Increment()
+	bar.Increment()
+	if len(wake) != 1 {
+		t.Errorf("Unexpected notifications: %d", len(wake))
+	}
+}
+
+func TestFrameTimerAdaptive(t *testing.T) {
+	timer := newFrameTimer(SystemClock, 100*time.Millisecond, time.Second, 0)
+	// fast writes keep the configured rate
+	timer.record(time.Millisecond, 100)
+	if a := timer.rate(); a != 100*time.Millisecond {
+		t.Errorf("Unexpected rate: %v", a)
+	}
+	// slow write lowers the rate at once, within bounds
+	timer.record(100*time.Millisecond, 5000)
+	if a := timer.rate(); a != 400*time.Millisecond {
+		t.Errorf("Unexpected rate: %v", a)
+	}
+	timer.record(time.Second, 5000)
+	if a := timer.rate(); a != time.Second {
+		t.Errorf("Unexpected rate: %v", a)
+	}
+	if s := timer.Stats(); s.WriteDuration != time.Second || s.Bytes != 5000 {
+		t.Errorf("Unexpected stats: %+v", s)
+	}
+	// and fast writes raise it back gradually
+	timer.record(time.Millisecond, 100)
+	if a := timer.rate(); a != 750*time.Millisecond {
+		t.Errorf("Unexpected rate: %v", a)
+	}
+	for i := 0; i < 10; i++ {
+		timer.record(time.Millisecond, 100)
+	}
+	if a := timer.rate(); a != 100*time.Millisecond {
+		t.Errorf("Unexpected rate: %v", a)
+	}
+	// negative max disables adaptation
+	timer = newFrameTimer(SystemClock, 100*time.Millisecond, -1, 0)
+	timer.record(time.Second, 100)
+	if a := timer.rate(); a != 100*time.Millisecond {
+		t.Errorf("Unexpected rate: %v", a)
+	}
+}
+
+func TestPBFrameStats(t *testing.T) {
+	clock := pbtest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	w := &slowWriter{clock: clock, delay: 50 * time.Millisecond}
+	bar := New(10).SetClock(clock).SetWriter(w).SetRefreshRate(10 * time.Millisecond).SetIdleRefreshRate(-1).Start()
+	bar.Increment()
+	if !clock.WaitTimers(1, time.Second) {
+		t.Fatalf("Writer doesn't wait on the clock")
+	}
+	clock.Advance(10 * time.Millisecond)
+	bar.Finish()
+	// writes take 50ms, so frames are drawn 4 times slower
+	s := bar.FrameStats()
+	if s.RefreshRate != 200*time.Millisecond || s.WriteDuration != 50*time.Millisecond || s.Bytes == 0 {
+		t.Errorf("Unexpected stats: %+v", s)
+	}
+	if w.writes < 2 {
+		t.Errorf("Unexpected writes count: %d", w.writes)
+	}
+}
+
+// slowWriter imitates slow output, each write advances the clock by delay
+type slowWriter struct {
+	clock  *pbtest.FakeClock
+	delay  time.Duration
+	writes int
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	w.writes++
+	w.clock.Advance(w.delay)
+	return len(p), nil
+}
@@ -70,14 +70,22 @@ func getSpeedObj(state *State) (s *speed) {
 }
 
 // ElementSpeed calculates current speed by EWMA
+// Speed is printed according to the bar unit and RatePeriod, like a "42 p/s", "1.20 MiB/s" or "3 files/min".
 // Optionally can take one or two string arguments.
-// First string will be used as value for format speed, default is "%s p/s".
-// Second string will be used when speed not available, default is "? p/s"
+// First string will be used as value for format speed number without unit suffix, by default unit suffix is used.
+// Second string will be used when speed not available, default is "? p/s" (or unit symbol instead of "p")
 // In template use as follows: {{speed .}} or {{speed . "%s per second"}} or {{speed . "%s ps" "..."}
 var ElementSpeed ElementFunc = func(state *State, args ...string) string {
 	sp := getSpeedObj(state).value(state)
 	if sp == 0 {
-		return argsHelper(args).getNotEmptyOr(1, "? p/s")
+		if f := argsHelper(args).getOr(1, ""); f != "" {
+			return state.locale().String(f)
+		}
+		return state.speedUnknown()
 	}
-	return fmt.Sprintf(argsHelper(args).getNotEmptyOr(0, "%s p/s"), state.Format(int64(round(sp))))
+	if f := argsHelper(args).getOr(0, ""); f != "" {
+		v, _ := state.ratePeriod(sp)
+		return fmt.Sprintf(state.locale().String(f), state.Format(int64(round(v))))
+	}
+	return state.FormatSpeed(sp)
 }
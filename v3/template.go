@@ -26,6 +26,53 @@ func (pbt ProgressBarTemplate) Start(total int) *ProgressBar {
 	return pbt.Start64(int64(total))
 }
 
+// ResponsiveTemplate is a template for bars at least MinWidth wide
+type ResponsiveTemplate struct {
+	MinWidth int
+	Template ProgressBarTemplate
+}
+
+type responsiveTemplate struct {
+	minWidth int
+	t        *template.Template
+}
+
+// SetResponsiveTemplates sets ordered list of templates, from the richest to the simplest one
+// On render the first template which MinWidth is not greater than bar width and which content fits
+// without clipping is used. The last template is used when nothing fits.
+// bar.SetResponsiveTemplates(pb.ResponsiveFull...)
+func (pb *ProgressBar) SetResponsiveTemplates(tmpls ...ResponsiveTemplate) *ProgressBar {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.tmpls = nil
+	for _, rt := range tmpls {
+		t, err := getTemplate(string(rt.Template))
+		if err != nil {
+			pb.tmpls, pb.err = nil, err
+			return pb
+		}
+		pb.tmpls = append(pb.tmpls, responsiveTemplate{minWidth: rt.MinWidth, t: t})
+	}
+	if len(pb.tmpls) > 0 {
+		pb.tmpl = pb.tmpls[0].t
+	}
+	return pb
+}
+
+// fitsWidth checks rendered template fits width without clipping
+// Adaptive elements need their minimal width or at least one cell
+func fitsWidth(result string, adaptive []adaptiveElement, width int) bool {
+	need := CellCount(result) - len(adaptive)*adElPlaceholderLen
+	for _, el := range adaptive {
+		if el.min > 1 {
+			need += el.min
+		} else {
+			need++
+		}
+	}
+	return need <= width
+}
+
 var templateCacheMu sync.Mutex
 var templateCache = make(map[string]*template.Template)
 
@@ -42,6 +89,10 @@ var defaultTemplateFuncs = template.FuncMap{
 	"resetcolor": color.New(color.Reset).SprintFunc(),
 	"rndcolor":   rndcolor,
 	"rnd":        rnd,
+	// adaptive elements layout
+	"weight":   adaptiveOptionFunc(AdaptiveWeight),
+	"minwidth": adaptiveOptionFunc(AdaptiveMinWidth),
+	"maxwidth": adaptiveOptionFunc(AdaptiveMaxWidth),
 }
 
 func getTemplate(tmpl string) (t *template.Template, err error) {
@@ -68,8 +119,10 @@ func fillTemplateFuncs(t *template.Template) {
 	emf := make(template.FuncMap)
 	elementsM.Lock()
 	for k, v := range elements {
-		element := v
-		emf[k] = func(state *State, args ...string) string { return element.ProgressElement(state, args...) }
+		name, element := k, v
+		emf[k] = func(state *State, args ...string) string {
+			return state.column(name, element.ProgressElement(state, args...))
+		}
 	}
 	elementsM.Unlock()
 	t.Funcs(emf)
@@ -2,6 +2,8 @@ package pb
 
 import (
 	"bytes"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -51,3 +53,60 @@ func TestTemplateFuncs(t *testing.T) {
 		t.Errorf("Unexpected rnd result: '%v'", r)
 	}
 }
+
+func TestResponsiveTemplates(t *testing.T) {
+	bar := New(10).SetResponsiveTemplates(
+		ResponsiveTemplate{MinWidth: 40, Template: `{{counters . }} wide`},
+		ResponsiveTemplate{Template: `{{counters . }} {{percent . }}`},
+		ResponsiveTemplate{Template: `{{counters . }}`},
+	)
+	if a, e := bar.SetWidth(40).String(), "0 / 10 wide"; a != e {
+		t.Errorf("Unexpected result: '%s'; want '%s'", a, e)
+	}
+	if a, e := bar.SetWidth(39).String(), "0 / 10 0.00%"; a != e {
+		t.Errorf("Unexpected result: '%s'; want '%s'", a, e)
+	}
+	if a, e := bar.SetWidth(8).String(), "0 / 10"; a != e {
+		t.Errorf("Unexpected result: '%s'; want '%s'", a, e)
+	}
+	bar.SetResponsiveTemplates(ResponsiveTemplate{Template: `{{invalid`})
+	if bar.Err() == nil {
+		t.Error("Must be error")
+	}
+	bar.SetTemplateString(`{{percent . }}`)
+	if a, e := bar.SetWidth(40).String(), "0.00%"; a != e {
+		t.Errorf("Unexpected result: '%s'; want '%s'", a, e)
+	}
+}
+
+func TestResponsiveTemplatesState(t *testing.T) {
+	// rejected templates don't advance cycles
+	bar := New(10).SetWidth(6).SetResponsiveTemplates(
+		ResponsiveTemplate{Template: `{{cycle . "a" "b" "c"}} too wide`},
+		ResponsiveTemplate{Template: `{{cycle . "1" "2" "3"}}`},
+	)
+	var frames []string
+	for i := 0; i < 3; i++ {
+		frames = append(frames, bar.String())
+	}
+	if a, e := strings.Join(frames, ","), "1,2,3"; a != e {
+		t.Errorf("Unexpected frames: '%s'; want '%s'", a, e)
+	}
+	// the chosen template advances cycles once
+	bar.SetWidth(40)
+	frames = frames[:0]
+	for i := 0; i < 3; i++ {
+		frames = append(frames, bar.String())
+	}
+	if a, e := strings.Join(frames, ","), "a too wide,b too wide,c too wide"; a != e {
+		t.Errorf("Unexpected frames: '%s'; want '%s'", a, e)
+	}
+
+	// an earlier error isn't cleared
+	err := errors.New("test error")
+	bar.SetErr(err)
+	bar.SetResponsiveTemplates(ResponsiveFull...)
+	if bar.Err() != err {
+		t.Errorf("Unexpected error: %v", bar.Err())
+	}
+}
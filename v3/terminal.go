@@ -0,0 +1,23 @@
+package pb
+
+import "github.com/cbehopkins/pb/v3/termutil"
+
+// SetTerminal sets the terminal the bar is drawn on, nil resets it to termutil.System.
+// It's used for width, terminal detection of the writer and resize notifications,
+// e.g. a termutil.Fake in tests.
+func (pb *ProgressBar) SetTerminal(t termutil.Terminal) *ProgressBar {
+	pb.mu.Lock()
+	pb.terminal = t
+	pb.configured = false
+	pb.configure()
+	pb.mu.Unlock()
+	return pb
+}
+
+// getTerminal returns the terminal of the bar, pb.mu must be held
+func (pb *ProgressBar) getTerminal() termutil.Terminal {
+	if pb.terminal == nil {
+		return termutil.System
+	}
+	return pb.terminal
+}
@@ -0,0 +1,200 @@
+package termutil
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// Fake is an in-memory Terminal for tests. It's a terminal for any writer
+// unless SetTerminal(false) is called.
+type Fake struct {
+	mu          sync.Mutex
+	rows, cols  int
+	raw         bool
+	input       bool
+	altScreen   io.Writer
+	inputR      *io.PipeReader
+	inputW      *io.PipeWriter
+	rawErr      error
+	notTerminal bool
+	resize      map[chan<- os.Signal]bool
+}
+
+// NewFake returns a fake terminal of the given size
+func NewFake(rows, cols int) *Fake {
+	return &Fake{rows: rows, cols: cols}
+}
+
+// Size returns the size set by NewFake or Resize
+func (f *Fake) Size() (rows, cols int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cols <= 0 {
+		return 0, 0, errors.New("terminal size unknown")
+	}
+	return f.rows, f.cols, nil
+}
+
+// Resize changes the size and notifies channels registered with NotifyResize
+func (f *Fake) Resize(rows, cols int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rows, f.cols = rows, cols
+	for c := range f.resize {
+		select {
+		case c <- resizeSignal{}:
+		default:
+		}
+	}
+}
+
+// RawModeOn switches the fake to raw mode, it fails when raw mode is already on
+// or with the error set by SetRawModeError
+func (f *Fake) RawModeOn() (quit chan struct{}, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.rawErr != nil {
+		return nil, f.rawErr
+	}
+	if f.raw {
+		return nil, errLocked
+	}
+	f.raw = true
+	quit = make(chan struct{}, 1)
+	go func() {
+		<-quit
+		f.RawModeOff()
+	}()
+	return
+}
+
+// RawModeOff restores the fake from raw mode
+func (f *Fake) RawModeOff() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.raw = false
+	f.input = false
+	if f.altScreen != nil {
+		io.WriteString(f.altScreen, altScreenOff)
+		f.altScreen = nil
+	}
+	if f.inputW != nil {
+		// readers of the input get io.EOF
+		f.inputW.Close()
+		f.inputR, f.inputW = nil, nil
+	}
+	return nil
+}
+
+// InputOn switches the fake in raw mode to reading keystrokes, they are sent by Type
+func (f *Fake) InputOn() (io.Reader, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.raw {
+		return nil, errNotRaw
+	}
+	f.input = true
+	f.pipe()
+	return f.inputR, nil
+}
+
+// AltScreenOn writes the same sequences as the system terminal to w, e.g. a pbtest.Screen
+func (f *Fake) AltScreenOn(w io.Writer) (err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.raw {
+		return errNotRaw
+	}
+	if f.altScreen != nil {
+		return errLocked
+	}
+	if _, err = io.WriteString(w, altScreenOn); err != nil {
+		return
+	}
+	f.altScreen = w
+	return
+}
+
+// IsAltScreen reports whether the alternate screen is on, see AltScreenOn
+func (f *Fake) IsAltScreen() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.altScreen != nil
+}
+
+// IsInput reports whether keystrokes are read, see InputOn
+func (f *Fake) IsInput() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.input
+}
+
+// Type sends keys to the reader returned by InputOn, it blocks until they are read.
+// Named keys are escape sequences, e.g. "\033[A" for the up arrow.
+func (f *Fake) Type(keys string) {
+	f.mu.Lock()
+	w := f.pipe()
+	f.mu.Unlock()
+	io.WriteString(w, keys)
+}
+
+// pipe returns the input pipe, f.mu must be held
+func (f *Fake) pipe() *io.PipeWriter {
+	if f.inputW == nil {
+		f.inputR, f.inputW = io.Pipe()
+	}
+	return f.inputW
+}
+
+// IsRaw reports whether the fake is in raw mode
+func (f *Fake) IsRaw() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.raw
+}
+
+// SetRawModeError makes RawModeOn fail with err, nil resets it
+func (f *Fake) SetRawModeError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rawErr = err
+}
+
+// SetTerminal sets whether writers are reported as the terminal, like a pipe or a file when false
+func (f *Fake) SetTerminal(terminal bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.notTerminal = !terminal
+}
+
+// IsTerminal reports whether writers are the terminal, see SetTerminal
+func (f *Fake) IsTerminal(w io.Writer) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !f.notTerminal
+}
+
+// NotifyResize relays Resize calls to c
+func (f *Fake) NotifyResize(c chan<- os.Signal) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.resize == nil {
+		f.resize = make(map[chan<- os.Signal]bool)
+	}
+	f.resize[c] = true
+}
+
+// StopResize stops relaying Resize calls to c
+func (f *Fake) StopResize(c chan<- os.Signal) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.resize, c)
+}
+
+// resizeSignal is sent by Fake on Resize
+type resizeSignal struct{}
+
+func (resizeSignal) String() string { return "resize" }
+func (resizeSignal) Signal()        {}
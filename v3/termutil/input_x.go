@@ -0,0 +1,104 @@
+//go:build (linux || darwin || freebsd || netbsd || openbsd || solaris || dragonfly || aix) && !appengine
+// +build linux darwin freebsd netbsd openbsd solaris dragonfly aix
+// +build !appengine
+
+package termutil
+
+import (
+	"io"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// cancelReader reads a file descriptor, Close unblocks a pending Read without consuming input.
+// Read polls the descriptor together with a self-pipe, Close closes the write end of the pipe.
+type cancelReader struct {
+	fd      int
+	r, w    int
+	mu      sync.Mutex
+	reading bool
+	closed  bool
+}
+
+// openInput returns a cancelReader of fd as the terminal input, echoLockMutex must be held
+func openInput(fd int) (io.Reader, error) {
+	if input != nil {
+		input.Close()
+	}
+	r, err := newCancelReader(fd)
+	if err != nil {
+		return nil, err
+	}
+	input = r
+	return r, nil
+}
+
+func newCancelReader(fd int) (*cancelReader, error) {
+	var p [2]int
+	if err := unix.Pipe(p[:]); err != nil {
+		return nil, err
+	}
+	return &cancelReader{fd: fd, r: p[0], w: p[1]}, nil
+}
+
+// Read waits until the descriptor is readable and reads it, it returns io.EOF once closed
+func (c *cancelReader) Read(b []byte) (n int, err error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return 0, io.EOF
+	}
+	c.reading = true
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.reading = false
+		if c.closed {
+			// Close left the read end of the pipe to the pending Read
+			unix.Close(c.r)
+			if n == 0 {
+				err = io.EOF
+			}
+		}
+		c.mu.Unlock()
+	}()
+	fds := []unix.PollFd{
+		{Fd: int32(c.fd), Events: unix.POLLIN},
+		{Fd: int32(c.r), Events: unix.POLLIN},
+	}
+	for {
+		if _, err = unix.Poll(fds, -1); err == unix.EINTR {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		if fds[1].Revents != 0 {
+			return 0, io.EOF
+		}
+		if fds[0].Revents != 0 {
+			break
+		}
+	}
+	if n, err = unix.Read(c.fd, b); err != nil {
+		return 0, err
+	}
+	if n == 0 && len(b) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// Close unblocks a pending Read, the descriptor itself isn't closed
+func (c *cancelReader) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	if !c.reading {
+		unix.Close(c.r)
+	}
+	return unix.Close(c.w)
+}
@@ -0,0 +1,58 @@
+//go:build (linux || darwin || freebsd || netbsd || openbsd || solaris || dragonfly || aix) && !appengine
+// +build linux darwin freebsd netbsd openbsd solaris dragonfly aix
+// +build !appengine
+
+package termutil
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCancelReader(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.Close()
+	defer pw.Close()
+	r, err := newCancelReader(int(pr.Fd()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	buf := make([]byte, 8)
+	pw.WriteString("q")
+	if n, err := r.Read(buf); err != nil || string(buf[:n]) != "q" {
+		t.Errorf("Unexpected read: %q, %v", buf[:n], err)
+	}
+
+	// Close unblocks a pending Read
+	done := make(chan error)
+	go func() {
+		_, err := r.Read(buf)
+		done <- err
+	}()
+	r.Close()
+	select {
+	case err := <-done:
+		if err != io.EOF {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Read isn't unblocked by Close")
+	}
+	if _, err := r.Read(buf); err != io.EOF {
+		t.Errorf("Unexpected error after Close: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// the next keystroke isn't swallowed by the closed reader
+	pw.WriteString("x")
+	if n, err := pr.Read(buf); err != nil || string(buf[:n]) != "x" {
+		t.Errorf("Unexpected read: %q, %v", buf[:n], err)
+	}
+}
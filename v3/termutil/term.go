@@ -2,6 +2,7 @@ package termutil
 
 import (
 	"errors"
+	"io"
 	"os"
 	"os/signal"
 	"sync"
@@ -10,7 +11,19 @@ import (
 var echoLocked bool
 var echoLockMutex sync.Mutex
 var errLocked = errors.New("terminal locked")
+var errNotRaw = errors.New("terminal isn't in raw mode")
 var autoTerminate = true
+var altScreen io.Writer
+
+// input is the reader returned by InputOn, RawModeOff closes it to unblock its Read
+var input io.Closer
+
+const (
+	// switch to the alternate screen buffer, clear it and hide the cursor
+	altScreenOn = "\033[?1049h\033[H\033[2J\033[?25l"
+	// show the cursor and switch back to the original screen
+	altScreenOff = "\033[?25h\033[?1049l"
+)
 
 // AutoTerminate enables or disables automatic terminate signal catching.
 // It's needed to restore the terminal state after the pool was used.
@@ -45,6 +58,14 @@ func RawModeOff() (err error) {
 	if !echoLocked {
 		return
 	}
+	if altScreen != nil {
+		io.WriteString(altScreen, altScreenOff)
+		altScreen = nil
+	}
+	if input != nil {
+		input.Close()
+		input = nil
+	}
 	if err = unlockEcho(); err != nil {
 		return
 	}
@@ -52,6 +73,36 @@ func RawModeOff() (err error) {
 	return
 }
 
+// InputOn switches the terminal in raw mode to reading keystrokes one by one, without echo
+// and without signals for Ctrl-C, and returns the terminal input. RawModeOff restores the mode
+// and pending reads of the input return io.EOF.
+func InputOn() (io.Reader, error) {
+	echoLockMutex.Lock()
+	defer echoLockMutex.Unlock()
+	if !echoLocked {
+		return nil, errNotRaw
+	}
+	return inputOn()
+}
+
+// AltScreenOn switches w, the terminal output, to the alternate screen buffer and hides the cursor.
+// The terminal must be in raw mode, RawModeOff restores the original screen, also on exit signals.
+func AltScreenOn(w io.Writer) (err error) {
+	echoLockMutex.Lock()
+	defer echoLockMutex.Unlock()
+	if !echoLocked {
+		return errNotRaw
+	}
+	if altScreen != nil {
+		return errLocked
+	}
+	if _, err = io.WriteString(w, altScreenOn); err != nil {
+		return
+	}
+	altScreen = w
+	return
+}
+
 // listen exit signals and restore terminal state
 func catchTerminate(quit chan struct{}) {
 	sig := make(chan os.Signal, 1)
@@ -66,3 +117,16 @@ func catchTerminate(quit chan struct{}) {
 		RawModeOff()
 	}
 }
+
+// NotifyResize relays terminal resize signals (SIGWINCH) to c.
+// It's a no-op on platforms where resizing isn't signaled.
+func NotifyResize(c chan<- os.Signal) {
+	if len(resizeSignals) > 0 {
+		signal.Notify(c, resizeSignals...)
+	}
+}
+
+// StopResize stops relaying resize signals to c
+func StopResize(c chan<- os.Signal) {
+	signal.Stop(c)
+}
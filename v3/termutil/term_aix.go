@@ -3,6 +3,7 @@
 package termutil
 
 import (
+	"io"
 	"os"
 	"syscall"
 
@@ -10,20 +11,12 @@ import (
 )
 
 var (
-	tty *os.File
-
 	unlockSignals = []os.Signal{
 		os.Interrupt, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGKILL,
 	}
-)
 
-func init() {
-	var err error
-	tty, err = os.Open("/dev/tty")
-	if err != nil {
-		tty = os.Stdin
-	}
-}
+	resizeSignals = []os.Signal{syscall.SIGWINCH}
+)
 
 // TerminalWidth returns width of the terminal.
 func TerminalWidth() (int, error) {
@@ -33,7 +26,7 @@ func TerminalWidth() (int, error) {
 
 // TerminalSize returns size of the terminal.
 func TerminalSize() (int, int, error) {
-	w, err := unix.IoctlGetWinsize(int(tty.Fd()), syscall.TIOCGWINSZ)
+	w, err := unix.IoctlGetWinsize(int(getTTY().Fd()), syscall.TIOCGWINSZ)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -43,7 +36,7 @@ func TerminalSize() (int, int, error) {
 var oldState unix.Termios
 
 func lockEcho() error {
-	fd := int(tty.Fd())
+	fd := int(getTTY().Fd())
 	currentState, err := unix.IoctlGetTermios(fd, unix.TCGETS)
 	if err != nil {
 		return err
@@ -61,9 +54,20 @@ func lockEcho() error {
 }
 
 func unlockEcho() (err error) {
-	fd := int(tty.Fd())
+	fd := int(getTTY().Fd())
 	if err := unix.IoctlSetTermios(fd, unix.TCSETS, &oldState); err != nil {
 		return err
 	}
 	return
 }
+
+func inputOn() (io.Reader, error) {
+	newState := oldState
+	newState.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.ISIG
+	newState.Cc[unix.VMIN] = 1
+	newState.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(int(getTTY().Fd()), unix.TCSETS, &newState); err != nil {
+		return nil, err
+	}
+	return openInput(int(getTTY().Fd()))
+}
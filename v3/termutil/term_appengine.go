@@ -3,10 +3,23 @@
 
 package termutil
 
-import "errors"
+import (
+	"errors"
+	"io"
+)
 
 // terminalWidth returns width of the terminal, which is not supported
 // and should always failed on appengine classic which is a sandboxed PaaS.
 func TerminalWidth() (int, error) {
 	return 0, errors.New("Not supported")
 }
+
+// TerminalSize returns size of the terminal, which is not supported.
+func TerminalSize() (rows, cols int, err error) {
+	return 0, 0, errors.New("Not supported")
+}
+
+// inputOn isn't supported, keystrokes can't be read.
+func inputOn() (io.Reader, error) {
+	return nil, errors.New("Not supported")
+}
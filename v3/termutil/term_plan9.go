@@ -2,6 +2,7 @@ package termutil
 
 import (
 	"errors"
+	"io"
 	"os"
 	"syscall"
 )
@@ -13,6 +14,9 @@ var (
 	unlockSignals = []os.Signal{
 		os.Interrupt, syscall.SIGTERM, syscall.SIGKILL,
 	}
+
+	// terminal resize isn't signaled
+	resizeSignals []os.Signal
 )
 
 // TerminalWidth returns width of the terminal.
@@ -48,3 +52,13 @@ func unlockEcho() error {
 	consctl = nil
 	return nil
 }
+
+// TerminalSize returns size of the terminal, which is not supported.
+func TerminalSize() (rows, cols int, err error) {
+	return 0, 0, errors.New("Not supported")
+}
+
+// inputOn isn't supported, keystrokes can't be read.
+func inputOn() (io.Reader, error) {
+	return nil, errors.New("Not supported")
+}
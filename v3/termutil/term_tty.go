@@ -0,0 +1,26 @@
+//go:build (linux || darwin || freebsd || netbsd || openbsd || solaris || dragonfly || aix) && !appengine
+// +build linux darwin freebsd netbsd openbsd solaris dragonfly aix
+// +build !appengine
+
+package termutil
+
+import (
+	"os"
+	"sync"
+)
+
+var (
+	tty     *os.File
+	ttyOnce sync.Once
+)
+
+// getTTY opens the controlling terminal on first use, stdin is used when it can't be opened
+func getTTY() *os.File {
+	ttyOnce.Do(func() {
+		var err error
+		if tty, err = os.Open("/dev/tty"); err != nil {
+			tty = os.Stdin
+		}
+	})
+	return tty
+}
@@ -4,7 +4,9 @@
 package termutil
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strconv"
@@ -18,6 +20,9 @@ var (
 	unlockSignals = []os.Signal{
 		os.Interrupt, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGKILL,
 	}
+
+	// terminal resize isn't signaled
+	resizeSignals []os.Signal
 )
 
 var (
@@ -85,6 +90,12 @@ func TerminalWidth() (width int, err error) {
 	return termWidthCmd()
 }
 
+// TerminalSize returns width of the terminal, the height isn't reported and rows is 0.
+func TerminalSize() (rows, cols int, err error) {
+	cols, err = TerminalWidth()
+	return
+}
+
 func termWidthCmd() (width int, err error) {
 	var info consoleScreenBufferInfo
 	_, _, e := syscall.Syscall(procGetConsoleScreenBufferInfo.Addr(), 2, uintptr(syscall.Stdout), uintptr(unsafe.Pointer(&info)), 0)
@@ -153,3 +164,8 @@ func unlockEcho() (err error) {
 	}
 	return
 }
+
+// inputOn isn't supported, keystrokes can't be read.
+func inputOn() (io.Reader, error) {
+	return nil, errors.New("Not supported")
+}
@@ -6,18 +6,19 @@ package termutil
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"syscall"
 	"unsafe"
 )
 
 var (
-	tty *os.File
-
 	unlockSignals = []os.Signal{
 		os.Interrupt, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGKILL,
 	}
-	oldState syscall.Termios
+
+	resizeSignals = []os.Signal{syscall.SIGWINCH}
+	oldState      syscall.Termios
 )
 
 type window struct {
@@ -27,14 +28,6 @@ type window struct {
 	Ypixel uint16
 }
 
-func init() {
-	var err error
-	tty, err = os.Open("/dev/tty")
-	if err != nil {
-		tty = os.Stdin
-	}
-}
-
 // TerminalWidth returns width of the terminal.
 func TerminalWidth() (int, error) {
 	_, c, err := TerminalSize()
@@ -45,7 +38,7 @@ func TerminalWidth() (int, error) {
 func TerminalSize() (rows, cols int, err error) {
 	w := new(window)
 	res, _, err := syscall.Syscall(sysIoctl,
-		tty.Fd(),
+		getTTY().Fd(),
 		uintptr(syscall.TIOCGWINSZ),
 		uintptr(unsafe.Pointer(w)),
 	)
@@ -56,7 +49,7 @@ func TerminalSize() (rows, cols int, err error) {
 }
 
 func lockEcho() error {
-	fd := tty.Fd()
+	fd := getTTY().Fd()
 
 	if _, _, err := syscall.Syscall(sysIoctl, fd, ioctlReadTermios, uintptr(unsafe.Pointer(&oldState))); err != 0 {
 		return fmt.Errorf("error when puts the terminal connected to the given file descriptor: %w", err)
@@ -73,9 +66,20 @@ func lockEcho() error {
 }
 
 func unlockEcho() error {
-	fd := tty.Fd()
+	fd := getTTY().Fd()
 	if _, _, err := syscall.Syscall(sysIoctl, fd, ioctlWriteTermios, uintptr(unsafe.Pointer(&oldState))); err != 0 {
 		return fmt.Errorf("error restores the terminal connected to the given file descriptor: %w", err)
 	}
 	return nil
 }
+
+func inputOn() (io.Reader, error) {
+	newState := oldState
+	newState.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.ISIG
+	newState.Cc[syscall.VMIN] = 1
+	newState.Cc[syscall.VTIME] = 0
+	if _, _, e := syscall.Syscall(sysIoctl, getTTY().Fd(), ioctlWriteTermios, uintptr(unsafe.Pointer(&newState))); e != 0 {
+		return nil, fmt.Errorf("error update terminal settings: %w", e)
+	}
+	return openInput(int(getTTY().Fd()))
+}
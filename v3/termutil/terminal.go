@@ -0,0 +1,49 @@
+package termutil
+
+import (
+	"io"
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+// Terminal is a terminal progress bars are drawn on.
+// System is the terminal of the process, Fake is an in-memory terminal for tests.
+type Terminal interface {
+	// Size returns height and width of the terminal, rows is 0 when the height is unknown
+	Size() (rows, cols int, err error)
+	// RawModeOn switches the terminal to raw mode, it's restored when quit is closed
+	RawModeOn() (quit chan struct{}, err error)
+	// RawModeOff restores previous terminal state
+	RawModeOff() error
+	// InputOn switches the terminal in raw mode to reading keystrokes one by one
+	// and returns the terminal input, RawModeOff restores it and unblocks reads with io.EOF
+	InputOn() (io.Reader, error)
+	// AltScreenOn switches w, the terminal output, to the alternate screen buffer
+	// and hides the cursor, RawModeOff restores the original screen
+	AltScreenOn(w io.Writer) error
+	// IsTerminal reports whether w writes to the terminal
+	IsTerminal(w io.Writer) bool
+	// NotifyResize relays terminal resizes to c
+	NotifyResize(c chan<- os.Signal)
+	// StopResize stops relaying resizes to c
+	StopResize(c chan<- os.Signal)
+}
+
+// System is the terminal of the process, backed by the package functions
+var System Terminal = system{}
+
+type system struct{}
+
+func (system) Size() (rows, cols int, err error)          { return TerminalSize() }
+func (system) RawModeOn() (quit chan struct{}, err error) { return RawModeOn() }
+func (system) RawModeOff() error                          { return RawModeOff() }
+func (system) InputOn() (io.Reader, error)                { return InputOn() }
+func (system) AltScreenOn(w io.Writer) error              { return AltScreenOn(w) }
+func (system) NotifyResize(c chan<- os.Signal)            { NotifyResize(c) }
+func (system) StopResize(c chan<- os.Signal)              { StopResize(c) }
+
+func (system) IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
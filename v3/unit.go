@@ -0,0 +1,197 @@
+package pb
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Unit describes how bar values and speed are printed
+// Select unit by bar.Set(pb.Units, "files") or bar.Set(pb.Units, myUnit)
+type Unit struct {
+	// Name of the unit in the registry
+	Name string
+
+	// Symbol appended to the plain numbers: "files" gives "3 files/s"
+	// Defaults to Name
+	Symbol string
+
+	// Format prints value, e.g. counters. When nil the bar count formatting is used (see Counts)
+	Format func(pb *ProgressBar, v int64) string
+
+	// FormatRate prints speed value without period, e.g. "1.20 MiB" or "3 files"
+	// When nil Format and Symbol are used
+	FormatRate func(pb *ProgressBar, v float64) string
+}
+
+func (u *Unit) symbol() string {
+	if u.Symbol != "" {
+		return u.Symbol
+	}
+	return u.Name
+}
+
+func (u *Unit) format(pb *ProgressBar, v int64) string {
+	if u.Format != nil {
+		return u.Format(pb, v)
+	}
+	return pb.formatCount(v)
+}
+
+func (u *Unit) formatRate(pb *ProgressBar, v float64) string {
+	if u.FormatRate != nil {
+		return u.FormatRate(pb, v)
+	}
+	return pb.Format(int64(round(v))) + " " + pb.locale().Unit(u.symbol())
+}
+
+var (
+	// UnitItems is the default unit: plain numbers, speed as "42 p/s"
+	UnitItems = &Unit{Name: "items", Symbol: "p"}
+
+	// UnitBytes prints values with IEC prefixes: "1.20 MiB", "1.20 MiB/s"
+	UnitBytes = &Unit{
+		Name:   "bytes",
+		Symbol: "B",
+		Format: func(pb *ProgressBar, v int64) string {
+			return pb.locale().Number(pb.bytesFormat(false).format(v))
+		},
+		FormatRate: func(pb *ProgressBar, v float64) string {
+			return pb.locale().Number(pb.bytesFormat(false).format(int64(round(v))))
+		},
+	}
+
+	// UnitBytesSI prints values with SI prefixes: "1.26 MB", "1.26 MB/s"
+	UnitBytesSI = &Unit{
+		Name:   "bytes-si",
+		Symbol: "B",
+		Format: func(pb *ProgressBar, v int64) string {
+			return pb.locale().Number(pb.bytesFormat(true).format(v))
+		},
+		FormatRate: func(pb *ProgressBar, v float64) string {
+			return pb.locale().Number(pb.bytesFormat(true).format(int64(round(v))))
+		},
+	}
+
+	// UnitBits takes values in bytes and prints them as bits, handy for network rates: "9.60 Mbit/s"
+	UnitBits = &Unit{
+		Name:   "bits",
+		Symbol: "bit",
+		Format: func(pb *ProgressBar, v int64) string {
+			return pb.locale().Number(formatBits(float64(v) * 8))
+		},
+		FormatRate: func(pb *ProgressBar, v float64) string {
+			return pb.locale().Number(formatBits(v * 8))
+		},
+	}
+
+	// UnitDuration takes values as time.Duration: "1m30s", "1.5s/s"
+	UnitDuration = &Unit{
+		Name:   "duration",
+		Symbol: "s",
+		Format: func(pb *ProgressBar, v int64) string {
+			return pb.locale().FormatDuration(time.Duration(v))
+		},
+		FormatRate: func(pb *ProgressBar, v float64) string {
+			return pb.locale().FormatDuration(time.Duration(v).Round(time.Millisecond))
+		},
+	}
+)
+
+var unitsM sync.Mutex
+
+var units = map[string]*Unit{
+	UnitItems.Name:    UnitItems,
+	UnitBytes.Name:    UnitBytes,
+	UnitBytesSI.Name:  UnitBytesSI,
+	UnitBits.Name:     UnitBits,
+	UnitDuration.Name: UnitDuration,
+}
+
+// RegisterUnit adds the unit to the registry, so it can be selected by name
+// pb.RegisterUnit(pb.Unit{Name: "files"}); bar.Set(pb.Units, "files")
+func RegisterUnit(u Unit) {
+	unitsM.Lock()
+	units[u.Name] = &u
+	unitsM.Unlock()
+}
+
+// LookupUnit returns registered unit by name or nil
+func LookupUnit(name string) *Unit {
+	unitsM.Lock()
+	defer unitsM.Unlock()
+	return units[name]
+}
+
+// AutoRatePeriod can be set as RatePeriod to switch speed to per minute or per hour for slow jobs
+const AutoRatePeriod time.Duration = -1
+
+var ratePeriods = []struct {
+	d      time.Duration
+	suffix string
+}{
+	{time.Second, "s"},
+	{time.Minute, "min"},
+	{time.Hour, "h"},
+}
+
+// unit returns the unit selected by Units, or the bytes unit when Bytes is set
+func (pb *ProgressBar) unit() *Unit {
+	switch u := pb.Get(Units).(type) {
+	case *Unit:
+		if u != nil {
+			return u
+		}
+	case Unit:
+		return &u
+	case string:
+		if u := LookupUnit(u); u != nil {
+			return u
+		}
+	}
+	if pb.GetBool(Bytes) {
+		if pb.GetBool(SIBytesPrefix) {
+			return UnitBytesSI
+		}
+		return UnitBytes
+	}
+	return UnitItems
+}
+
+// ratePeriod converts per second speed to the period selected by RatePeriod
+func (pb *ProgressBar) ratePeriod(perSecond float64) (v float64, suffix string) {
+	period, _ := pb.Get(RatePeriod).(time.Duration)
+	if period == AutoRatePeriod && perSecond > 0 {
+		for _, rp := range ratePeriods {
+			v, suffix = perSecond*rp.d.Seconds(), rp.suffix
+			if v >= 1 {
+				return
+			}
+		}
+		return
+	}
+	for _, rp := range ratePeriods {
+		if rp.d == period {
+			return perSecond * rp.d.Seconds(), rp.suffix
+		}
+	}
+	if period > 0 {
+		return perSecond * period.Seconds(), period.String()
+	}
+	return perSecond, "s"
+}
+
+// FormatSpeed convert speed given per second to string according to the current unit and RatePeriod
+// Like a "42 p/s", "1.20 MiB/s", "3 files/min"
+func (pb *ProgressBar) FormatSpeed(perSecond float64) string {
+	u := pb.unit()
+	v, suffix := pb.ratePeriod(perSecond)
+	return fmt.Sprintf("%s/%s", u.formatRate(pb, v), pb.locale().Unit(suffix))
+}
+
+// speedUnknown returns speed placeholder for the current unit, like a "? p/s"
+func (pb *ProgressBar) speedUnknown() string {
+	_, suffix := pb.ratePeriod(0)
+	l := pb.locale()
+	return l.String(fmt.Sprintf("? %s/%s", l.Unit(pb.unit().symbol()), l.Unit(suffix)))
+}
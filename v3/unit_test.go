@@ -0,0 +1,112 @@
+package pb
+
+import (
+	"testing"
+	"time"
+)
+
+// testRegisterUnit registers the unit for the test, the registry is restored on cleanup
+func testRegisterUnit(t *testing.T, u Unit) {
+	unitsM.Lock()
+	prev, ok := units[u.Name]
+	unitsM.Unlock()
+	RegisterUnit(u)
+	t.Cleanup(func() {
+		unitsM.Lock()
+		defer unitsM.Unlock()
+		if ok {
+			units[u.Name] = prev
+		} else {
+			delete(units, u.Name)
+		}
+	})
+}
+
+func TestUnitFormat(t *testing.T) {
+	testRegisterUnit(t, Unit{Name: "files"})
+	inputs := []struct {
+		unit any
+		v    int64
+		e    string
+	}{
+		{unit: nil, v: 1234, e: "1234"},
+		{unit: "items", v: 1234, e: "1234"},
+		{unit: "bytes", v: 1024, e: "1.00 KiB"},
+		{unit: UnitBytesSI, v: 1024, e: "1.02 kB"},
+		{unit: "bits", v: 1200000, e: "9.60 Mbit"},
+		{unit: "duration", v: int64(90 * time.Second), e: "1m30s"},
+		{unit: "files", v: 1234, e: "1234"},
+		{unit: Unit{Name: "rows", Format: func(pb *ProgressBar, v int64) string { return "many" }}, v: 1234, e: "many"},
+		{unit: "unknown", v: 1234, e: "1234"},
+	}
+	for _, input := range inputs {
+		bar := new(ProgressBar).Set(Units, input.unit)
+		if a := bar.Format(input.v); a != input.e {
+			t.Errorf("Unexpected format for %v: actual: '%v'; expected: '%v'", input.unit, a, input.e)
+		}
+	}
+}
+
+func TestUnitRegistryCleanup(t *testing.T) {
+	t.Run("register", func(t *testing.T) {
+		testRegisterUnit(t, Unit{Name: "test-cleanup"})
+		testRegisterUnit(t, Unit{Name: "items", Format: func(pb *ProgressBar, v int64) string { return "x" }})
+	})
+	if LookupUnit("test-cleanup") != nil {
+		t.Errorf("Unexpected unit left in the registry")
+	}
+	if LookupUnit("items") != UnitItems {
+		t.Errorf("Unexpected items unit after cleanup")
+	}
+}
+
+func TestUnitFormatSpeed(t *testing.T) {
+	testRegisterUnit(t, Unit{Name: "files"})
+	inputs := []struct {
+		unit   any
+		bytes  bool
+		period time.Duration
+		v      float64
+		e      string
+	}{
+		{v: 42, e: "42 p/s"},
+		{bytes: true, v: 1.2 * _MiB, e: "1.20 MiB/s"},
+		{unit: "bytes-si", v: 1.2 * _MB, e: "1.20 MB/s"},
+		{unit: "bits", v: 1.2e6, e: "9.60 Mbit/s"},
+		{unit: "files", v: 3, e: "3 files/s"},
+		{unit: "files", period: time.Minute, v: 3, e: "180 files/min"},
+		{unit: "files", period: time.Hour, v: 0.01, e: "36 files/h"},
+		{unit: "files", period: AutoRatePeriod, v: 3, e: "3 files/s"},
+		{unit: "files", period: AutoRatePeriod, v: 0.1, e: "6 files/min"},
+		{unit: "files", period: AutoRatePeriod, v: 0.001, e: "4 files/h"},
+	}
+	for _, input := range inputs {
+		bar := new(ProgressBar).Set(Units, input.unit).Set(Bytes, input.bytes)
+		if input.period != 0 {
+			bar.Set(RatePeriod, input.period)
+		}
+		if a := bar.FormatSpeed(input.v); a != input.e {
+			t.Errorf("Unexpected speed: actual: '%v'; expected: '%v'", a, input.e)
+		}
+	}
+}
+
+func TestUnitElementSpeed(t *testing.T) {
+	var state = testState(1000, 0, 0, true)
+	state.time = time.Now()
+	if r, w := ElementSpeed(state), "? B/s"; r != w {
+		t.Errorf("Unexpected result: '%s' vs '%s'", r, w)
+	}
+	for i := int64(0); i < 3; i++ {
+		state.id = uint64(i) + 1
+		state.current += 2 * _MiB
+		state.time = state.time.Add(time.Second)
+		ElementSpeed(state)
+	}
+	if r, w := ElementSpeed(state), "2.00 MiB/s"; r != w {
+		t.Errorf("Unexpected result: '%s' vs '%s'", r, w)
+	}
+	if r, w := ElementSpeed(state, "%s per second"), "2.00 MiB per second"; r != w {
+		t.Errorf("Unexpected result: '%s' vs '%s'", r, w)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/mattn/go-runewidth"
 	"math"
 	"regexp"
+	"strconv"
+	"strings"
 	//"unicode/utf8"
 )
 
@@ -14,11 +16,15 @@ const (
 	_MiB = 1048576
 	_GiB = 1073741824
 	_TiB = 1099511627776
+	_PiB = 1125899906842624
+	_EiB = 1152921504606846976
 
 	_kB = 1e3
 	_MB = 1e6
 	_GB = 1e9
 	_TB = 1e12
+	_PB = 1e15
+	_EB = 1e18
 )
 
 var ctrlFinder = regexp.MustCompile("\x1b\x5b[0-9;]+\x6d")
@@ -31,6 +37,14 @@ func CellCount(s string) int {
 	return n
 }
 
+// wrappedRows returns count of terminal rows taken by a line of given cells at given width
+func wrappedRows(cells, width int) int {
+	if cells <= width || width <= 0 {
+		return 1
+	}
+	return (cells + width - 1) / width
+}
+
 func StripString(s string, w int) string {
 	l := CellCount(s)
 	if l <= w {
@@ -85,32 +99,115 @@ func round(val float64) (newVal float64) {
 // Convert bytes to human readable string. Like a 2 MiB, 64.2 KiB, or 2 MB, 64.2 kB
 // if useSIPrefix is set to true
 func formatBytes(i int64, useSIPrefix bool) (result string) {
-	if !useSIPrefix {
-		switch {
-		case i >= _TiB:
-			result = fmt.Sprintf("%.02f TiB", float64(i)/_TiB)
-		case i >= _GiB:
-			result = fmt.Sprintf("%.02f GiB", float64(i)/_GiB)
-		case i >= _MiB:
-			result = fmt.Sprintf("%.02f MiB", float64(i)/_MiB)
-		case i >= _KiB:
-			result = fmt.Sprintf("%.02f KiB", float64(i)/_KiB)
-		default:
-			result = fmt.Sprintf("%d B", i)
+	return bytesFormat{si: useSIPrefix, precision: 2}.format(i)
+}
+
+var (
+	// siPrefixes are SI prefixes of powers of 1000, shared by bytes, bits and counts
+	siPrefixes       = []string{"k", "M", "G", "T", "P", "E"}
+	iecBytesPrefixes = []string{"Ki", "Mi", "Gi", "Ti", "Pi", "Ei"}
+)
+
+// bytesFormat holds options of bytes formatting
+type bytesFormat struct {
+	si        bool // use SI prefixes (kB, MB) instead of IEC (KiB, MiB)
+	precision int  // digits after the decimal point
+	trim      bool // trim trailing zeros: 1.50 KiB -> 1.5 KiB
+	compact   bool // no space and prefix letter only: 1.5K
+}
+
+func (f bytesFormat) format(i int64) string {
+	base, prefixes := float64(_KiB), iecBytesPrefixes
+	if f.si {
+		base, prefixes = _kB, siPrefixes
+	}
+	if float64(i) < base {
+		if f.compact {
+			return fmt.Sprintf("%dB", i)
 		}
-	} else {
-		switch {
-		case i >= _TB:
-			result = fmt.Sprintf("%.02f TB", float64(i)/_TB)
-		case i >= _GB:
-			result = fmt.Sprintf("%.02f GB", float64(i)/_GB)
-		case i >= _MB:
-			result = fmt.Sprintf("%.02f MB", float64(i)/_MB)
-		case i >= _kB:
-			result = fmt.Sprintf("%.02f kB", float64(i)/_kB)
-		default:
-			result = fmt.Sprintf("%d B", i)
+		return fmt.Sprintf("%d B", i)
+	}
+	v := float64(i)
+	var prefix string
+	for _, prefix = range prefixes {
+		v /= base
+		if v < base {
+			break
 		}
 	}
-	return
+	num := strconv.FormatFloat(v, 'f', f.precision, 64)
+	if f.trim && strings.Contains(num, ".") {
+		num = strings.TrimRight(strings.TrimRight(num, "0"), ".")
+	}
+	if f.compact {
+		return num + prefix[:1]
+	}
+	return num + " " + prefix + "B"
+}
+
+// Convert bits to human readable string with SI prefix. Like a 512 bit, 9.60 Mbit
+func formatBits(v float64) string {
+	if math.Abs(v) < 1000 {
+		return fmt.Sprintf("%.0f bit", v)
+	}
+	var prefix string
+	for _, prefix = range siPrefixes {
+		v /= 1000
+		if math.Abs(v) < 1000 {
+			break
+		}
+	}
+	return fmt.Sprintf("%.02f %sbit", v, prefix)
+}
+
+// Convert count to short string with SI suffix. Like a 999, 12.3k or 4.6M
+func formatCountSI(i int64) string {
+	v := math.Abs(float64(i))
+	if v < 1000 {
+		return strconv.FormatInt(i, 10)
+	}
+	var suffix string
+	for _, suffix = range siPrefixes {
+		v /= 1000
+		// 999.95k would be printed as 1000.0k, so move it to the next suffix
+		if v < 999.95 {
+			break
+		}
+	}
+	if i < 0 {
+		v = -v
+	}
+	return fmt.Sprintf("%.1f%s", v, suffix)
+}
+
+// Convert count to string with digit groups separated by sep. Like a 12,345,678
+func formatCountGrouped(i int64, sep string) string {
+	s := strconv.FormatInt(i, 10)
+	var sign string
+	if i < 0 {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= 3 || sep == "" {
+		return sign + s
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	head := len(s) % 3
+	if head == 0 {
+		head = 3
+	}
+	b.WriteString(s[:head])
+	for j := head; j < len(s); j += 3 {
+		b.WriteString(sep)
+		b.WriteString(s[j : j+3])
+	}
+	return b.String()
+}
+
+// padLeft prepends spaces to s until it takes at least w cells
+func padLeft(s string, w int) string {
+	if r := w - CellCount(s); r > 0 {
+		return strings.Repeat(" ", r) + s
+	}
+	return s
 }
@@ -62,6 +62,11 @@ func TestUtilFormatBytes(t *testing.T) {
 		{v: 3*_MB + 140*_kB, s: true, e: "3.14 MB"},
 		{v: 2 * _GB, s: true, e: "2.00 GB"},
 		{v: 2048 * _GB, s: true, e: "2.05 TB"},
+
+		{v: 3 * _PiB, s: false, e: "3.00 PiB"},
+		{v: 7 * _EiB, s: false, e: "7.00 EiB"},
+		{v: 1500 * _TB, s: true, e: "1.50 PB"},
+		{v: 9 * _EB, s: true, e: "9.00 EB"},
 	}
 
 	for _, input := range inputs {
@@ -72,6 +77,66 @@ func TestUtilFormatBytes(t *testing.T) {
 	}
 }
 
+func TestUtilFormatCountSI(t *testing.T) {
+	for _, input := range []struct {
+		v int64
+		e string
+	}{
+		{v: 0, e: "0"},
+		{v: 999, e: "999"},
+		{v: 1000, e: "1.0k"},
+		{v: 12345, e: "12.3k"},
+		{v: 999949, e: "999.9k"},
+		{v: 999950, e: "1.0M"},
+		{v: 4567890, e: "4.6M"},
+		{v: 7 * 1e18, e: "7.0E"},
+		{v: -12345, e: "-12.3k"},
+	} {
+		if actual := formatCountSI(input.v); actual != input.e {
+			t.Errorf("Expected {%s} was {%s}", input.e, actual)
+		}
+	}
+}
+
+func TestUtilFormatCountGrouped(t *testing.T) {
+	for _, input := range []struct {
+		v   int64
+		sep string
+		e   string
+	}{
+		{v: 0, sep: ",", e: "0"},
+		{v: 999, sep: ",", e: "999"},
+		{v: 1000, sep: ",", e: "1,000"},
+		{v: 12345678, sep: ",", e: "12,345,678"},
+		{v: 123456, sep: ".", e: "123.456"},
+		{v: 1234567, sep: "\u202f", e: "1\u202f234\u202f567"},
+		{v: -1234567, sep: ",", e: "-1,234,567"},
+		{v: -123, sep: ",", e: "-123"},
+		{v: 1234567, sep: "", e: "1234567"},
+	} {
+		if actual := formatCountGrouped(input.v, input.sep); actual != input.e {
+			t.Errorf("Expected {%s} was {%s}", input.e, actual)
+		}
+	}
+}
+
+func TestUtilPadLeft(t *testing.T) {
+	for _, input := range []struct {
+		s string
+		w int
+		e string
+	}{
+		{s: "42", w: 5, e: "   42"},
+		{s: "12345", w: 3, e: "12345"},
+		{s: "進捗", w: 6, e: "  進捗"},
+		{s: color.RedString("1"), w: 3, e: "  " + color.RedString("1")},
+	} {
+		if actual := padLeft(input.s, input.w); actual != input.e {
+			t.Errorf("Expected {%s} was {%s}", input.e, actual)
+		}
+	}
+}
+
 func BenchmarkUtilsCellCount(b *testing.B) {
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {